@@ -2,64 +2,409 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"sw_call/internal/config"
+	"sw_call/internal/event"
+	"sw_call/internal/identity"
 	"sw_call/internal/initialize"
+	"sw_call/internal/paths"
+	"sw_call/internal/service/announce"
+	"sw_call/internal/service/caller"
+	"sw_call/internal/service/chime"
+	"sw_call/internal/service/diagnostics"
+	"sw_call/internal/service/display"
 	"sw_call/internal/service/local"
+	"sw_call/internal/service/logview"
+	"sw_call/internal/service/preference"
+	"sw_call/internal/service/printer"
+	"sw_call/internal/service/queue"
+	"sw_call/internal/service/register"
+	"sw_call/internal/service/remoteconfig"
+	"sw_call/internal/service/weblog"
+	"sw_call/pkg/audio"
+	"sw_call/pkg/logger"
 	"sw_call/pkg/storage"
+	"sw_call/pkg/tts"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// configReloadInterval 配置文件轮询间隔
+	configReloadInterval = 2 * time.Second
+	// trayDebugRevert 从托盘切换到 debug 后自动恢复的时间，避免长期输出大量日志
+	trayDebugRevert = 30 * time.Minute
 )
 
 // App 结构体 - 用于绑定到前端
 type App struct {
 	ctx          context.Context
-	cfg          *config.Config
+	clientID     string                        // 启动时由硬件指纹加载一次，之后不再重新采集
+	cfg          atomic.Pointer[config.Config] // 热加载在监视协程中替换，读取时通过 config() 取快照
+	cfgOptions   *config.Options
+	dirs         *paths.Paths
+	watcher      *config.Watcher
+	remote       *remoteconfig.Service
 	localService *local.Service
-	// caller caller.ProcessService
+	registerSvc  *register.Service
+	prefService  *preference.Service
+	announcer    *announce.Service
+	chime        *chime.Service
+	queueService *queue.Service
+	events       *event.Bus
+	display      *display.Service
+	printer      *printer.Service
+	caller       caller.ProcessService
+	weblog       *weblog.Service
+	logView      *logview.Service
+	tailMu       sync.Mutex
+	tailCancel   context.CancelFunc
+	diagnostics  *diagnostics.Service
 }
 
 // NewApp 创建新的应用实例
 func NewApp() *App {
-	return &App{}
+	return &App{
+		weblog: weblog.NewService(weblog.DefaultRate, weblog.DefaultBurst),
+	}
 }
 
-// Initialize 初始化应用（由 main.go 调用）
-func (a *App) Initialize(cfg *config.Config) {
-	a.cfg = cfg
+// Initialize 初始化应用（由 main.go 调用），数据存储无法打开时返回错误，应用不能继续启动
+func (a *App) Initialize(cfg *config.Config, dirs *paths.Paths) error {
+	a.cfg.Store(cfg)
+	a.dirs = dirs
 
 	// 初始化应用（日志、存储等）
-	initialize.InitApp("root")
+	if err := initialize.InitApp(dirs.Root(), &cfg.Logging); err != nil {
+		slog.Error("初始化应用失败", slog.String("错误信息", err.Error()))
+		return err
+	}
+	slog.Info("数据目录", "mode", dirs.Mode, "path", dirs.Base)
+	if dirs.MigratedFrom != "" {
+		slog.Info("已从旧目录迁移数据", "from", dirs.MigratedFrom, "to", dirs.Base)
+	}
+
+	// 加载客户端ID，不存在时由硬件指纹生成
+	if id, err := identity.LoadClientID(storage.GetInstance()); err != nil {
+		slog.Error("加载客户端ID失败", slog.String("错误信息", err.Error()))
+	} else {
+		a.clientID = id.ClientID
+		slog.Info("客户端ID", "client_id", id.ClientID)
+	}
+
+	// 叠加缓存的远程配置，需在创建其他服务之前
+	a.initRemoteConfig(cfg)
 
 	// 初始化本地数据服务
 	a.localService = local.NewService(storage.GetInstance())
+
+	// 初始化用户偏好服务
+	a.prefService = preference.NewService(storage.GetInstance())
+
+	// 初始化日志查询服务
+	a.logView = logview.NewService(cfg.Logging.FilePath)
+
+	// 初始化呼叫播报服务，没有 espeak-ng 或 espeak 时只播放提示音，调用方会收到不可用提示
+	var synth tts.Synthesizer = tts.NewNullSynthesizer()
+	if espeak := tts.NewEspeakSynthesizer(tts.EspeakConfig{}); espeak.Available() {
+		synth = espeak
+	} else {
+		slog.Warn("未找到 espeak-ng 或 espeak，语音播报不可用")
+	}
+	a.announcer = announce.NewService(synth)
+
+	// 初始化提示音服务，每次呼叫播报前先经播放队列播放提示音，静音时段内不播报
+	chimeService, err := chime.NewService(&cfg.Audio)
+	if err != nil {
+		slog.Error("初始化提示音服务失败", slog.String("错误信息", err.Error()))
+	} else {
+		a.chime = chimeService
+		a.announcer.SetMuted(a.chime.Muted)
+		a.announcer.SetPrelude(func(ctx context.Context, ann announce.Announcement) error {
+			if ann.Urgent {
+				return a.chime.PlayWait(ctx, chime.SoundUrgent, audio.PriorityUrgent)
+			}
+			return a.chime.PlayWait(ctx, chime.SoundChime, audio.PriorityNormal)
+		})
+	}
+
+	// 初始化下一位患者推荐服务
+	a.queueService = queue.NewService(cfg.Queue)
+
+	// 初始化患者事件总线，门头屏、凭条打印机等硬件输出订阅患者事件
+	a.events = event.NewBus()
+	if cfg.Display.Enabled {
+		displayService, err := display.NewService(cfg.Display)
+		if err != nil {
+			slog.Error("初始化门头屏失败", slog.String("错误信息", err.Error()))
+		} else {
+			a.display = displayService
+			a.display.Subscribe(a.events)
+		}
+	}
+	if cfg.Printer.Enabled {
+		printerService, err := printer.NewService(cfg.Printer)
+		if err != nil {
+			slog.Error("初始化凭条打印机失败", slog.String("错误信息", err.Error()))
+		} else {
+			a.printer = printerService
+			a.printer.Subscribe(a.events)
+		}
+	}
+
+	// 初始化呼叫进程服务
+	a.caller = caller.NewService(&cfg.Process)
+
+	// 初始化设备注册服务
+	if a.clientID != "" {
+		a.registerSvc = register.NewService(storage.GetInstance(), a.clientID, register.DefaultPollInterval)
+	}
+
+	// 初始化诊断包服务
+	a.diagnostics = diagnostics.NewService(diagnostics.Options{
+		Version:    a.GetVersion(),
+		ClientID:   a.clientID,
+		Config:     cfg,
+		Store:      storage.GetInstance(),
+		StorageDir: dirs.StorageDir(),
+		LogDir:     cfg.Logging.FilePath,
+	})
+	return nil
+}
+
+// config 返回当前配置的快照，同一次调用中只取一次，避免前后读到不同版本
+func (a *App) config() *config.Config {
+	return a.cfg.Load()
+}
+
+// EnableConfigReload 启用配置文件热加载，opts 与启动时加载配置的参数相同
+// 需在 Initialize 之前调用，远程配置依赖这些参数重新加载
+func (a *App) EnableConfigReload(opts config.Options) {
+	a.cfgOptions = &opts
+}
+
+// initRemoteConfig 创建远程配置服务，并用缓存的远程配置覆盖启动配置，服务器不可达时也能生效
+// 日志此时已按本地配置初始化，缓存的远程配置修改了日志设置时重新初始化日志
+func (a *App) initRemoteConfig(cfg *config.Config) {
+	if !cfg.Remote.Enabled || a.cfgOptions == nil || a.clientID == "" {
+		return
+	}
+
+	var err error
+	a.remote, err = remoteconfig.NewService(storage.GetInstance(), a.clientID, cfg.Remote)
+	if err != nil {
+		slog.Error("初始化远程配置失败", slog.String("错误信息", err.Error()))
+		return
+	}
+
+	doc := a.remote.Cached()
+	if doc == nil {
+		return
+	}
+	opts := *a.cfgOptions
+	opts.Remote = []byte(doc.Config)
+	result, err := config.LoadLayered(opts)
+	if err != nil {
+		slog.Error("缓存的远程配置无效，使用本地配置", "version", doc.Version, "error", err)
+		return
+	}
+
+	a.cfgOptions.Remote = opts.Remote
+	localLogging := cfg.Logging
+	*cfg = *result.Config
+	if !reflect.DeepEqual(localLogging, cfg.Logging) {
+		if err := initialize.InitLogger(&cfg.Logging); err != nil {
+			// 继续使用本地的日志设置，配置快照与实际输出保持一致
+			slog.Error("按远程配置初始化日志失败，使用本地日志配置", "error", err)
+			cfg.Logging = localLogging
+		}
+	}
+	slog.Info("使用缓存的远程配置", "version", doc.Version, "overridden", result.Overridden(config.SourceRemote))
+}
+
+// applyRemoteConfig 应用服务端下发的配置，可在线生效的配置项由热加载处理
+func (a *App) applyRemoteConfig(doc *remoteconfig.Document) error {
+	if a.watcher == nil {
+		return fmt.Errorf("未启用配置热加载")
+	}
+
+	var data []byte
+	if doc != nil {
+		data = []byte(doc.Config)
+	}
+	result, err := a.watcher.SetRemote(data)
+	if err != nil {
+		return err
+	}
+
+	if doc == nil {
+		slog.Info("已移除远程配置，恢复本地配置")
+		return nil
+	}
+	slog.Info("远程配置覆盖的配置项", "version", doc.Version, "overridden", result.Overridden(config.SourceRemote))
+	return nil
 }
 
 // startup 在应用启动时调用
 func (a *App) startup(ctx context.Context) {
+	// 之后使用 a.ctx 记录的日志都带上 client_id
+	if a.clientID != "" {
+		ctx = logger.WithClientID(ctx, a.clientID)
+	}
 	a.ctx = ctx
 
 	// 设置托盘上下文
 	SetContext(ctx)
+	cfg := a.config()
 
 	// 初始化系统托盘
-	InitTray(&a.cfg.Tray)
+	InitTray(&cfg.Tray, TrayActions{
+		OnLogLevel: func(level string) {
+			var revert time.Duration
+			if level == "debug" {
+				revert = trayDebugRevert
+			}
+			if err := a.setLogLevel(level, revert); err != nil {
+				slog.Error("切换日志级别失败", "level", level, "error", err)
+			}
+		},
+		OnDiagnostics: a.trayDiagnostics,
+	})
+
+	// 日志级别变化时通知前端
+	logger.OnLevelChange(func(slog.Level) {
+		runtime.EventsEmit(ctx, "log:level", a.logLevelStatus())
+	})
+
+	// 启动设备注册状态轮询，状态变化时通知前端
+	if a.registerSvc != nil {
+		a.registerSvc.OnChange(func(status register.Status) {
+			runtime.EventsEmit(ctx, "register:status", status)
+		})
+		go a.registerSvc.Run(ctx)
+	}
+
+	// 启动呼叫播报队列
+	go a.announcer.Run(ctx)
+	if a.chime != nil {
+		go a.chime.Run(ctx)
+	}
 
-	// 唤醒呼叫进程
-	// if err := a.caller.Start(ctx); err != nil {
-	// 	slog.Error("启动呼叫进程失败", slog.String("错误信息", err.Error()))
-	// } else {
-	// 	slog.Info("呼叫进程启动成功", slog.Any("端口", a.caller.GetPort()))
-	// }
+	// 监视配置文件变化
+	if a.cfgOptions != nil {
+		a.watcher = config.NewWatcher(*a.cfgOptions, cfg, configReloadInterval, a.applyConfig)
+		go a.watcher.Run(ctx)
+	}
+
+	// 定期拉取远程配置
+	if a.remote != nil {
+		a.remote.OnUpdate(a.applyRemoteConfig)
+		go a.remote.Run(ctx)
+	}
+
+	// 偏好变化时通知前端
+	a.prefService.OnChange(func(change preference.Change) {
+		runtime.EventsEmit(ctx, "preferences:changed", change)
+	})
+
+	// 唤醒呼叫进程，启动重试较慢，不阻塞窗口显示
+	go func() {
+		if err := a.caller.Start(ctx); err != nil {
+			slog.Error("启动呼叫进程失败", slog.String("错误信息", err.Error()))
+		}
+	}()
 }
 
 // shutdown 在应用关闭时调用
 func (a *App) shutdown(ctx context.Context) {
 	slog.Info("应用关闭")
 
-	// 停止呼叫进程 测试
-	// if err := a.caller.Stop(ctx); err != nil {
-	// 	slog.Error("停止呼叫进程失败", slog.Any("失败原因", err.Error()))
-	// }
+	if a.display != nil {
+		a.display.Close()
+	}
+
+	// 停止呼叫进程
+	stopCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	if err := a.caller.Stop(stopCtx); err != nil {
+		slog.Error("停止呼叫进程失败", slog.Any("失败原因", err.Error()))
+	}
+
+	a.StopLogTail()
+
+	// 写完队列中的日志并关闭日志文件
+	if err := logger.Close(3 * time.Second); err != nil {
+		fmt.Fprintln(os.Stderr, "关闭日志失败:", err)
+	}
+}
+
+// applyConfig 应用热加载后的配置，无法在线生效的配置项提示需要重启
+// 快照中只更新已在线生效的配置项，需要重启的保持实际运行的值，避免诊断和设置页显示未生效的配置
+func (a *App) applyConfig(old, new *config.Config, changes []config.Change) {
+	var applied []string
+	trayChanged, sizeChanged, limitChanged := false, false, false
+
+	for _, change := range changes {
+		if change.RequiresRestart() {
+			continue
+		}
+		applied = append(applied, change.Path)
+
+		switch change.Path {
+		case "logging.level":
+			if level, err := logger.ParseLevel(new.Logging.Level); err == nil {
+				logger.SetLevel(level)
+			}
+		case "tray.title", "tray.tooltip", "tray.icon":
+			trayChanged = true
+		case "app.title":
+			runtime.WindowSetTitle(a.ctx, new.App.Title)
+		case "app.always_on_top":
+			runtime.WindowSetAlwaysOnTop(a.ctx, new.App.AlwaysOnTop)
+		case "app.width", "app.height":
+			sizeChanged = true
+		case "app.min_width", "app.min_height", "app.max_width", "app.max_height":
+			limitChanged = true
+		}
+	}
+
+	if trayChanged {
+		UpdateTray(&new.Tray)
+	}
+	if limitChanged {
+		runtime.WindowSetMinSize(a.ctx, new.App.MinWidth, new.App.MinHeight)
+		runtime.WindowSetMaxSize(a.ctx, new.App.MaxWidth, new.App.MaxHeight)
+	}
+	if sizeChanged {
+		runtime.WindowSetSize(a.ctx, new.App.Width, new.App.Height)
+	}
+
+	effective := config.MergeLive(a.config(), new)
+	a.cfg.Store(effective)
+	if a.diagnostics != nil {
+		a.diagnostics.SetConfig(effective)
+	}
+
+	// 与实际运行的配置比较，包括之前几次修改中仍待重启的配置项
+	var restart []string
+	for _, change := range config.Diff(effective, new) {
+		restart = append(restart, change.Path)
+	}
+	slog.Info("配置已重新加载", "applied", applied, "restart_required", restart)
+	runtime.EventsEmit(a.ctx, "config:reloaded", map[string]any{
+		"applied":          applied,
+		"restart_required": restart,
+	})
 }
 
 // beforeClose 在窗口关闭前调用，返回 true 可阻止窗口关闭
@@ -75,14 +420,22 @@ func (a *App) GetVersion() string {
 
 // ========== 本地数据相关方法 ==========
 
-// LoadClientID 加载客户端ID
+// LoadClientID 返回启动时加载的客户端ID
 func (a *App) LoadClientID() *local.Response {
-	return a.localService.LoadClientID()
+	if a.clientID == "" {
+		return local.NewErrorResponse("生成客户端ID失败")
+	}
+	return local.NewSuccessResponse(a.clientID)
 }
 
 // SaveForwardURL 保存服务器地址
 func (a *App) SaveForwardURL(url string) *local.Response {
-	return a.localService.SaveForwardURL(url)
+	resp := a.localService.SaveForwardURL(url)
+	if resp.Code == 200 && a.registerSvc != nil {
+		// 服务器地址变化后重新检查注册状态
+		a.registerSvc.Refresh()
+	}
+	return resp
 }
 
 // LoadForwardURL 加载服务器地址
@@ -109,3 +462,370 @@ func (a *App) DeleteLocaldata(id string) *local.Response {
 func (a *App) GetLocaldataList() *local.Response {
 	return a.localService.GetLocaldataList()
 }
+
+// ========== 设备注册相关方法 ==========
+
+// GetRegistrationStatus 获取设备注册状态
+func (a *App) GetRegistrationStatus() *local.Response {
+	if a.registerSvc == nil {
+		return local.NewErrorResponse("设备注册服务未初始化")
+	}
+	return local.NewSuccessResponse(a.registerSvc.Status())
+}
+
+// RefreshRegistration 立即重新检查设备注册状态
+func (a *App) RefreshRegistration() *local.Response {
+	if a.registerSvc == nil {
+		return local.NewErrorResponse("设备注册服务未初始化")
+	}
+	a.registerSvc.Refresh()
+	return local.NewSuccessResponse(nil)
+}
+
+// IsWorkbenchAllowed 设备是否已激活，未激活时前端应阻止进入工作台
+func (a *App) IsWorkbenchAllowed() bool {
+	return a.registerSvc != nil && a.registerSvc.Status().WorkbenchAllowed()
+}
+
+// ========== 用户偏好相关方法 ==========
+
+// GetPreferenceSchema 获取偏好字段定义
+func (a *App) GetPreferenceSchema() *local.Response {
+	return local.NewSuccessResponse(preference.Schema())
+}
+
+// GetPreferences 获取医生的偏好设置
+func (a *App) GetPreferences(doctorID int) *local.Response {
+	prefs, err := a.prefService.Get(doctorID)
+	if err != nil {
+		slog.ErrorContext(logger.WithDocID(a.ctx, doctorID), "获取偏好设置失败", "error", err)
+		return local.NewErrorResponse("获取偏好设置失败")
+	}
+	return local.NewSuccessResponse(prefs)
+}
+
+// UpdatePreferences 更新医生的部分偏好设置
+func (a *App) UpdatePreferences(doctorID int, changes map[string]any) *local.Response {
+	prefs, err := a.prefService.Update(doctorID, changes)
+	if err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(prefs)
+}
+
+// ResetPreferences 恢复默认偏好设置，keys 为空时恢复全部
+func (a *App) ResetPreferences(doctorID int, keys []string) *local.Response {
+	prefs, err := a.prefService.Reset(doctorID, keys...)
+	if err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(prefs)
+}
+
+// ========== 呼叫播报相关方法 ==========
+
+// AnnounceCall 按医生的播报模板和音量播报呼叫，urgent 为 true 时使用紧急提示音
+func (a *App) AnnounceCall(doctorID int, call announce.Call, repeat int, urgent bool) *local.Response {
+	prefs, err := a.prefService.Get(doctorID)
+	if err != nil {
+		slog.ErrorContext(logger.WithDocID(a.ctx, doctorID), "获取偏好设置失败", "error", err)
+		return local.NewErrorResponse("获取偏好设置失败")
+	}
+
+	if err := a.announcer.AnnounceCall(prefs.CallTemplate, call, repeat, prefs.VoiceVolume, urgent); err != nil {
+		return local.NewErrorResponse("呼叫播报失败: " + err.Error())
+	}
+	text := announce.Render(prefs.CallTemplate, call)
+	if !a.announcer.Available() {
+		return local.NewResponse(503, ttsUnavailable, text)
+	}
+	return local.NewSuccessResponse(text)
+}
+
+// ttsUnavailable 没有语音合成器时返回给前端的提示
+const ttsUnavailable = "语音播报不可用（未安装 espeak-ng 或 espeak），只播放提示音"
+
+// RecallAnnouncement 重新播报上一条呼叫
+func (a *App) RecallAnnouncement() *local.Response {
+	if err := a.announcer.Recall(); err != nil {
+		return local.NewErrorResponse("重呼失败: " + err.Error())
+	}
+	if !a.announcer.Available() {
+		return local.NewResponse(503, ttsUnavailable, nil)
+	}
+	return local.NewSuccessResponse(nil)
+}
+
+// PlaySound 播放提示音
+func (a *App) PlaySound(name string, urgent bool) *local.Response {
+	if a.chime == nil {
+		return local.NewErrorResponse("提示音服务未初始化")
+	}
+
+	priority := audio.PriorityNormal
+	if urgent {
+		priority = audio.PriorityUrgent
+	}
+	if err := a.chime.Play(name, priority); err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(nil)
+}
+
+// ========== 患者推荐相关方法 ==========
+
+// RecommendNextPatients 按本地规则对排队列表给出推荐顺序
+func (a *App) RecommendNextPatients(patients []queue.Patient) *local.Response {
+	return local.NewSuccessResponse(a.queueService.Recommend(patients))
+}
+
+// MarkPatientAbsent 标记或取消标记患者未到
+func (a *App) MarkPatientAbsent(appointmentID string, absent bool) *local.Response {
+	if appointmentID == "" {
+		return local.NewErrorResponse("预约ID不能为空")
+	}
+	a.queueService.MarkAbsent(appointmentID, absent)
+	return local.NewSuccessResponse(nil)
+}
+
+// ========== 患者事件相关方法 ==========
+
+// NotifyPatientEvent 前端呼叫、过号、结诊、转诊成功后通知，触发门头屏、凭条打印等硬件输出
+func (a *App) NotifyPatientEvent(e event.Event) *local.Response {
+	a.events.Publish(e)
+	return local.NewSuccessResponse(nil)
+}
+
+// PrintSlip 手动打印排队凭条
+func (a *App) PrintSlip(slip printer.Slip) *local.Response {
+	if a.printer == nil {
+		return local.NewErrorResponse("凭条打印机未启用")
+	}
+	if err := a.printer.Print(slip); err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(nil)
+}
+
+// ========== 配置编辑相关方法 ==========
+
+// GetConfigSchema 获取配置的 JSON Schema，readOnly 为 false 的字段可通过 UpdateConfig 修改
+func (a *App) GetConfigSchema() *local.Response {
+	return local.NewSuccessResponse(config.JSONSchema())
+}
+
+// GetEditableConfig 获取设置页可修改的窗口和托盘配置，路径为配置文件中的原始值
+func (a *App) GetEditableConfig() *local.Response {
+	current, _, err := a.loadLayered()
+	if err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(current.Raw.Editable())
+}
+
+// UpdateConfig 校验并写回 app.toml，保留原有注释，文件变化后由热加载生效
+func (a *App) UpdateConfig(changes map[string]any) *local.Response {
+	// 重新分层加载以取得每项的来源，被环境变量、命令行或远程配置覆盖的项不能写入文件
+	current, opts, err := a.loadLayered()
+	if err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	next, err := config.SaveEdits(current, opts, changes)
+	if err != nil {
+		slog.Error("保存配置失败", "path", current.Path, "error", err)
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(next.Editable())
+}
+
+// loadLayered 按启动参数重新分层加载配置，取得每项的来源和解析相对路径前的值
+func (a *App) loadLayered() (*config.Result, config.Options, error) {
+	if a.cfgOptions == nil || a.cfgOptions.Path == "" {
+		return nil, config.Options{}, errors.New("未找到配置文件路径")
+	}
+	opts := *a.cfgOptions
+	if a.watcher != nil {
+		opts = a.watcher.Options()
+	}
+	current, err := config.LoadLayered(opts)
+	if err != nil {
+		return nil, opts, fmt.Errorf("加载当前配置失败: %w", err)
+	}
+	return current, opts, nil
+}
+
+// ========== 日志级别相关方法 ==========
+
+// GetLogLevel 获取当前日志级别、配置的级别和临时级别的恢复时间
+func (a *App) GetLogLevel() *local.Response {
+	return local.NewSuccessResponse(a.logLevelStatus())
+}
+
+// SetLogLevel 运行时切换日志级别，revertMinutes 大于 0 时到期后恢复为配置的级别
+func (a *App) SetLogLevel(level string, revertMinutes int) *local.Response {
+	if err := a.setLogLevel(level, time.Duration(revertMinutes)*time.Minute); err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(a.logLevelStatus())
+}
+
+func (a *App) setLogLevel(name string, revert time.Duration) error {
+	if !slices.Contains(trayLogLevels, name) {
+		return fmt.Errorf("不支持的日志级别: %q", name)
+	}
+	level, err := logger.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	if revert <= 0 {
+		logger.SetLevel(level)
+		slog.Info("日志级别已切换", "level", name)
+		return nil
+	}
+
+	configured, err := logger.ParseLevel(a.config().Logging.Level)
+	if err != nil {
+		configured = slog.LevelInfo
+	}
+	logger.SetLevelFor(level, revert, configured)
+	slog.Info("日志级别已临时切换", "level", name, "revert_after", revert.String())
+	return nil
+}
+
+func (a *App) logLevelStatus() map[string]any {
+	status := map[string]any{
+		"level":      logger.LevelName(logger.GetLevel()),
+		"configured": a.config().Logging.Level,
+		"levels":     trayLogLevels,
+		"revert_at":  nil,
+	}
+	if at := logger.RevertAt(); !at.IsZero() {
+		status["revert_at"] = at
+	}
+	return status
+}
+
+// QueryLogs 按时间、级别、文本和属性查询日志文件，结果按时间倒序分页
+func (a *App) QueryLogs(query logview.Query) *local.Response {
+	if !a.fileLogging() {
+		return local.NewErrorResponse("未启用文件日志")
+	}
+	result, err := a.logView.Query(query)
+	if err != nil {
+		slog.Error("查询日志失败", "error", err)
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(result)
+}
+
+// StartLogTail 开始实时日志，满足条件的新日志通过 log:tail 事件推送，重复调用时替换之前的条件
+func (a *App) StartLogTail(query logview.Query) *local.Response {
+	if !a.fileLogging() {
+		return local.NewErrorResponse("未启用文件日志")
+	}
+	if err := query.Validate(); err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+
+	a.tailMu.Lock()
+	defer a.tailMu.Unlock()
+	if a.tailCancel != nil {
+		a.tailCancel()
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.tailCancel = cancel
+
+	emit := func(entries []logview.Entry) {
+		runtime.EventsEmit(a.ctx, "log:tail", entries)
+	}
+	go a.logView.Tail(ctx, query, logview.DefaultTailInterval, emit)
+	return local.NewSuccessResponse(nil)
+}
+
+// StopLogTail 停止实时日志
+func (a *App) StopLogTail() *local.Response {
+	a.tailMu.Lock()
+	defer a.tailMu.Unlock()
+	if a.tailCancel != nil {
+		a.tailCancel()
+		a.tailCancel = nil
+	}
+	return local.NewSuccessResponse(nil)
+}
+
+func (a *App) fileLogging() bool {
+	output := a.config().Logging.Output
+	return a.logView != nil && (output == "file" || output == "both")
+}
+
+// ========== 诊断包相关方法 ==========
+
+// ExportDiagnostics 生成诊断包，upload 为 true 且配置了上传地址时同时上传
+func (a *App) ExportDiagnostics(upload bool) *local.Response {
+	result, err := a.exportDiagnostics(upload)
+	if err != nil {
+		return local.NewResponse(500, err.Error(), result)
+	}
+	return local.NewSuccessResponse(result)
+}
+
+// exportDiagnostics 返回诊断包路径和是否已上传，上传失败时仍返回已生成的路径
+func (a *App) exportDiagnostics(upload bool) (map[string]any, error) {
+	if a.diagnostics == nil || a.dirs == nil {
+		return nil, fmt.Errorf("诊断包服务未初始化")
+	}
+	path, err := a.diagnostics.Export(filepath.Join(a.dirs.Root(), "diagnostics"))
+	if err != nil {
+		slog.Error("生成诊断包失败", "error", err)
+		return nil, fmt.Errorf("生成诊断包失败: %w", err)
+	}
+	slog.Info("已生成诊断包", "path", path)
+
+	result := map[string]any{"path": path, "uploaded": false}
+	if !upload || !a.diagnostics.CanUpload() {
+		return result, nil
+	}
+	if err := a.diagnostics.Upload(a.ctx, path); err != nil {
+		slog.Error("上传诊断包失败", "path", path, "error", err)
+		return result, err
+	}
+	slog.Info("已上传诊断包", "path", path)
+	result["uploaded"] = true
+	return result, nil
+}
+
+// trayDiagnostics 托盘菜单导出诊断包，配置了上传地址时自动上传，完成后弹窗提示
+func (a *App) trayDiagnostics() {
+	result, err := a.exportDiagnostics(true)
+	dialog := runtime.MessageDialogOptions{Type: runtime.InfoDialog, Title: "诊断包"}
+	switch {
+	case result == nil:
+		dialog.Type = runtime.ErrorDialog
+		dialog.Message = err.Error()
+	case err != nil:
+		dialog.Type = runtime.WarningDialog
+		dialog.Message = fmt.Sprintf("诊断包已保存到:\n%s\n\n%v", result["path"], err)
+	case result["uploaded"] == true:
+		dialog.Message = fmt.Sprintf("诊断包已上传，并保存到:\n%s", result["path"])
+	default:
+		dialog.Message = fmt.Sprintf("诊断包已保存到:\n%s", result["path"])
+	}
+	if _, err := runtime.MessageDialog(a.ctx, dialog); err != nil {
+		slog.Error("显示诊断包结果失败", "error", err)
+	}
+}
+
+// ========== 前端日志相关方法 ==========
+
+// Log 将前端日志写入日志文件，level 为 debug、info、warn、error，attrs 为附加的结构化属性
+func (a *App) Log(level, message string, attrs map[string]any) *local.Response {
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := a.weblog.Log(ctx, level, message, attrs); err != nil {
+		return local.NewErrorResponse(err.Error())
+	}
+	return local.NewSuccessResponse(nil)
+}
@@ -12,11 +12,15 @@ import {
 } from "@/wails/wailsjs/go/main/App";
 import { LogInfo } from "@/wails/wailsjs/runtime/runtime";
 import Message from "@/utils/message";
+import { installErrorCapture } from "@/utils/logger";
 
 import "@/assets/css/main.css";
 
 const app = createApp(App);
 
+// 前端异常和 console.error 写入客户端日志文件
+installErrorCapture(app);
+
 // 先注册 pinia
 const pinia = createPinia();
 pinia.use(piniaPluginPersistedstate);
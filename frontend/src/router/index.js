@@ -1,6 +1,7 @@
 import { createRouter, createWebHashHistory } from "vue-router";
 import CONSTANTS from "@/constants";
 import { useUserStore } from "@/stores";
+import { isWorkbenchAllowed, onRegistrationChange } from "@/utils/registration";
 
 const routes = [
   {
@@ -17,6 +18,7 @@ const routes = [
     path: "/workbench",
     name: "Workbench",
     component: () => import("@/views/Layout.vue"),
+    meta: { requiresActivation: true },
   },
 ];
 
@@ -53,8 +55,24 @@ router.beforeEach(async (to, _from, next) => {
 
   if (!to.meta.public && !isLoggedIn) {
     next({ name: "Login" });
-  } else {
-    next();
+    return;
+  }
+
+  // 设备未激活（未注册、待审核、已停用）时不允许进入工作台
+  if (to.meta.requiresActivation && !(await isWorkbenchAllowed())) {
+    next({ name: "Login" });
+    return;
+  }
+  next();
+});
+
+// 工作台中设备被停用或删除时退回登录页
+onRegistrationChange((status) => {
+  if (
+    status?.state !== "active" &&
+    router.currentRoute.value.meta.requiresActivation
+  ) {
+    router.push({ name: "Login" });
   }
 });
 
@@ -12,6 +12,7 @@ import {
   apiDoctorVisitedPatient,
 } from "@/api";
 import { setHandler, getOrgDocsStatusTopic } from "@/mqtt";
+import { notifyPatientEvent, PatientEvent } from "@/utils/hardware";
 
 export const usePatientStore = defineStore(
   "patient",
@@ -96,6 +97,7 @@ export const usePatientStore = defineStore(
         };
         const { code, data } = await apiPatCall(params);
         console.log("呼叫患者", data);
+        notifyPatientEvent(PatientEvent.CALL, data || patient);
 
         // 切换回候诊 tab
         setActiveTab("waiting", docId);
@@ -127,6 +129,7 @@ export const usePatientStore = defineStore(
 
         const { code, data } = await apiPatCall(params);
         console.log("呼叫患者", data);
+        notifyPatientEvent(PatientEvent.CALL, targetPatient);
 
         // 更新呼叫次数
         const callCountKey =
@@ -153,6 +156,7 @@ export const usePatientStore = defineStore(
           doc_id: docId,
         };
         await apiPatPass(params);
+        notifyPatientEvent(PatientEvent.PASS, patient);
 
         // 查询列表
         // await fetchPatients(docId);
@@ -178,6 +182,7 @@ export const usePatientStore = defineStore(
           doc_id: docId,
         };
         await apiPatEnd(params);
+        notifyPatientEvent(PatientEvent.END, patient);
 
         // 查询列表
         await fetchPatients(docId);
@@ -245,7 +250,13 @@ export const usePatientStore = defineStore(
           old_doc_id: docId,
         };
         // 调用转诊 API
-        await apiAssignRoom(params);
+        const { data } = await apiAssignRoom(params);
+        // 转诊后在新诊室重新排队，凭条使用新分配的排队号，未返回时不打印
+        notifyPatientEvent(
+          PatientEvent.MOVE,
+          { ...patient, line_num: data?.line_num ?? "" },
+          info.name
+        );
 
         // 更新患者列表
         await fetchPatients(docId);
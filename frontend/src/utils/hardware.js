@@ -0,0 +1,65 @@
+// 患者事件通知客户端，触发门头屏、凭条打印等硬件输出
+import { NotifyPatientEvent, PrintSlip } from "@/wails/wailsjs/go/main/App";
+import { useUserStore } from "@/stores/user";
+
+// 患者事件类型，与 internal/event 一致
+export const PatientEvent = {
+  CALL: "call",
+  PASS: "pass",
+  END: "end",
+  MOVE: "move",
+};
+
+/**
+ * 由患者数据构建事件
+ * @param {string} type 事件类型
+ * @param {Object} patient 患者
+ * @param {string} room 诊室名称，为空时使用当前诊室
+ */
+const toEvent = (type, patient, room) => {
+  const userStore = useUserStore();
+  return {
+    type,
+    number: String(patient?.line_num ?? ""),
+    name: patient?.name || "",
+    room: room || userStore.room?.name || "",
+    visit_id: String(patient?.appointment_id ?? ""),
+  };
+};
+
+/**
+ * 通知患者事件，硬件输出失败不影响呼叫流程
+ * @param {string} type 事件类型
+ * @param {Object} patient 患者
+ * @param {string} room 诊室名称，转诊时为新诊室
+ */
+export const notifyPatientEvent = async (type, patient, room = "") => {
+  if (!patient || !window?.go?.main?.App?.NotifyPatientEvent) {
+    return;
+  }
+  try {
+    await NotifyPatientEvent(toEvent(type, patient, room));
+  } catch (error) {
+    console.warn("通知患者事件失败:", error);
+  }
+};
+
+/**
+ * 手动打印排队凭条
+ * @param {Object} patient 患者
+ * @returns {Promise<{success: boolean, message?: string}>}
+ */
+export const printSlip = async (patient) => {
+  const event = toEvent(PatientEvent.CALL, patient);
+  const res = await PrintSlip({
+    room: event.room,
+    number: event.number,
+    name: event.name,
+    visit_id: event.visit_id,
+    time: new Date().toISOString(),
+  });
+  if (res?.code !== 200) {
+    return { success: false, message: res?.message || "打印凭条失败" };
+  }
+  return { success: true };
+};
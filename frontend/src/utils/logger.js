@@ -0,0 +1,105 @@
+// 前端日志上报，写入客户端的日志文件（source=frontend）
+import { Log } from "@/wails/wailsjs/go/main/App";
+
+/**
+ * 将任意值转换为可序列化的日志属性
+ */
+const toAttr = (value) => {
+  if (value instanceof Error) {
+    return `${value.name}: ${value.message}\n${value.stack || ""}`;
+  }
+  if (value === undefined || typeof value === "function") {
+    return String(value);
+  }
+  try {
+    JSON.stringify(value);
+    return value;
+  } catch {
+    return String(value);
+  }
+};
+
+/**
+ * 写入一条日志，Wails runtime 未就绪或上报失败时忽略
+ * @param {"debug"|"info"|"warn"|"error"} level 日志级别
+ * @param {string} message 消息
+ * @param {Object} attrs 结构化属性
+ */
+export const log = (level, message, attrs = {}) => {
+  if (!window?.go?.main?.App?.Log) {
+    return;
+  }
+  const data = {};
+  for (const [key, value] of Object.entries(attrs || {})) {
+    data[key] = toAttr(value);
+  }
+  data.page = window.location.hash || window.location.pathname;
+  Log(level, String(message), data).catch(() => {});
+};
+
+export const logger = {
+  debug: (message, attrs) => log("debug", message, attrs),
+  info: (message, attrs) => log("info", message, attrs),
+  warn: (message, attrs) => log("warn", message, attrs),
+  error: (message, attrs) => log("error", message, attrs),
+};
+
+/**
+ * console.error("获取患者列表失败:", error) 这类调用：第一个字符串作为消息，其余参数作为属性
+ */
+const forwardConsole = (level, args) => {
+  const [first, ...rest] = args;
+  const message =
+    typeof first === "string" ? first.replace(/[:：]\s*$/, "") : "console";
+  const values = typeof first === "string" ? rest : args;
+  const attrs = {};
+  values.forEach((value, i) => {
+    attrs[values.length === 1 ? "detail" : `detail_${i}`] = toAttr(value);
+  });
+  log(level, message, attrs);
+};
+
+/**
+ * 捕获未处理的异常、Promise 拒绝和 Vue 组件错误，并转发 console.error、console.warn
+ * @param {import("vue").App} app Vue 应用实例
+ */
+export const installErrorCapture = (app) => {
+  // 组件错误已单独上报，控制台输出使用未转发的 console.error
+  const consoleError = console.error.bind(console);
+
+  window.addEventListener("error", (event) => {
+    log("error", event.message || "未处理的异常", {
+      kind: "uncaught",
+      url: event.filename,
+      line: event.lineno,
+      col: event.colno,
+      stack: event.error?.stack,
+    });
+  });
+
+  window.addEventListener("unhandledrejection", (event) => {
+    const reason = event.reason;
+    log("error", reason?.message || "未处理的 Promise 拒绝", {
+      kind: "unhandledrejection",
+      reason: toAttr(reason),
+    });
+  });
+
+  app.config.errorHandler = (err, instance, info) => {
+    log("error", err?.message || "组件错误", {
+      kind: "vue",
+      component: instance?.$options?.name || instance?.$options?.__name,
+      info,
+      stack: err?.stack,
+    });
+    consoleError(err);
+  };
+
+  for (const level of ["error", "warn"]) {
+    const original = console[level].bind(console);
+    console[level] = (...args) => {
+      original(...args);
+      forwardConsole(level, args);
+    };
+  }
+};
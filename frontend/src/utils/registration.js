@@ -0,0 +1,63 @@
+// 设备注册状态，由客户端的注册服务轮询服务端，未激活的设备不能进入工作台
+import {
+  GetRegistrationStatus,
+  IsWorkbenchAllowed,
+} from "@/wails/wailsjs/go/main/App";
+import { EventsOn } from "@/wails/wailsjs/runtime/runtime";
+
+// 各状态的默认提示
+const stateMessages = {
+  unregistered: "设备未注册",
+  pending: "设备等待管理员审核",
+  disabled: "设备已被停用，请联系管理员",
+};
+
+// 浏览器中调试时没有客户端，不做限制
+const hasClient = () => !!window?.go?.main?.App?.IsWorkbenchAllowed;
+
+/**
+ * 是否允许进入工作台
+ * @returns {Promise<boolean>}
+ */
+export const isWorkbenchAllowed = async () => {
+  if (!hasClient()) {
+    return true;
+  }
+  try {
+    return await IsWorkbenchAllowed();
+  } catch (error) {
+    console.error("检查设备激活状态失败:", error);
+    return false;
+  }
+};
+
+/**
+ * 获取注册状态及未激活时的提示
+ * @returns {Promise<{allowed: boolean, message: string}>}
+ */
+export const checkRegistration = async () => {
+  if (!hasClient()) {
+    return { allowed: true, message: "" };
+  }
+  const res = await GetRegistrationStatus();
+  if (res?.code !== 200) {
+    return { allowed: false, message: res?.message || "设备注册服务不可用" };
+  }
+  const status = res.data;
+  const allowed = status.state === "active";
+  return {
+    allowed,
+    message: allowed ? "" : status.message || stateMessages[status.state],
+  };
+};
+
+/**
+ * 监听注册状态变化
+ * @param {(status: Object) => void} handler
+ */
+export const onRegistrationChange = (handler) => {
+  if (!hasClient()) {
+    return;
+  }
+  EventsOn("register:status", handler);
+};
@@ -1,15 +1,41 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {announce} from '../models';
 import {local} from '../models';
 import {config} from '../models';
+import {paths} from '../models';
+import {event} from '../models';
+import {printer} from '../models';
+import {logview} from '../models';
+import {queue} from '../models';
+
+export function AnnounceCall(arg1:number,arg2:announce.Call,arg3:number,arg4:boolean):Promise<local.Response>;
 
 export function DeleteLocaldata(arg1:string):Promise<local.Response>;
 
+export function EnableConfigReload(arg1:config.Options):Promise<void>;
+
+export function ExportDiagnostics(arg1:boolean):Promise<local.Response>;
+
+export function GetConfigSchema():Promise<local.Response>;
+
+export function GetEditableConfig():Promise<local.Response>;
+
 export function GetLocaldataList():Promise<local.Response>;
 
+export function GetLogLevel():Promise<local.Response>;
+
+export function GetPreferenceSchema():Promise<local.Response>;
+
+export function GetPreferences(arg1:number):Promise<local.Response>;
+
+export function GetRegistrationStatus():Promise<local.Response>;
+
 export function GetVersion():Promise<string>;
 
-export function Initialize(arg1:config.Config):Promise<void>;
+export function Initialize(arg1:config.Config,arg2:paths.Paths):Promise<void>;
+
+export function IsWorkbenchAllowed():Promise<boolean>;
 
 export function LoadClientID():Promise<local.Response>;
 
@@ -17,6 +43,36 @@ export function LoadForwardURL():Promise<local.Response>;
 
 export function LoadLocaldata(arg1:string):Promise<local.Response>;
 
+export function Log(arg1:string,arg2:string,arg3:Record<string, any>):Promise<local.Response>;
+
+export function MarkPatientAbsent(arg1:string,arg2:boolean):Promise<local.Response>;
+
+export function NotifyPatientEvent(arg1:event.Event):Promise<local.Response>;
+
+export function PlaySound(arg1:string,arg2:boolean):Promise<local.Response>;
+
+export function PrintSlip(arg1:printer.Slip):Promise<local.Response>;
+
+export function QueryLogs(arg1:logview.Query):Promise<local.Response>;
+
+export function RecallAnnouncement():Promise<local.Response>;
+
+export function RecommendNextPatients(arg1:Array<queue.Patient>):Promise<local.Response>;
+
+export function RefreshRegistration():Promise<local.Response>;
+
+export function ResetPreferences(arg1:number,arg2:Array<string>):Promise<local.Response>;
+
 export function SaveForwardURL(arg1:string):Promise<local.Response>;
 
 export function SaveLocaldata(arg1:string,arg2:string,arg3:any):Promise<local.Response>;
+
+export function SetLogLevel(arg1:string,arg2:number):Promise<local.Response>;
+
+export function StartLogTail(arg1:logview.Query):Promise<local.Response>;
+
+export function StopLogTail():Promise<local.Response>;
+
+export function UpdateConfig(arg1:Record<string, any>):Promise<local.Response>;
+
+export function UpdatePreferences(arg1:number,arg2:Record<string, any>):Promise<local.Response>;
@@ -2,20 +2,60 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AnnounceCall(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['AnnounceCall'](arg1, arg2, arg3, arg4);
+}
+
 export function DeleteLocaldata(arg1) {
   return window['go']['main']['App']['DeleteLocaldata'](arg1);
 }
 
+export function EnableConfigReload(arg1) {
+  return window['go']['main']['App']['EnableConfigReload'](arg1);
+}
+
+export function ExportDiagnostics(arg1) {
+  return window['go']['main']['App']['ExportDiagnostics'](arg1);
+}
+
+export function GetConfigSchema() {
+  return window['go']['main']['App']['GetConfigSchema']();
+}
+
+export function GetEditableConfig() {
+  return window['go']['main']['App']['GetEditableConfig']();
+}
+
 export function GetLocaldataList() {
   return window['go']['main']['App']['GetLocaldataList']();
 }
 
+export function GetLogLevel() {
+  return window['go']['main']['App']['GetLogLevel']();
+}
+
+export function GetPreferenceSchema() {
+  return window['go']['main']['App']['GetPreferenceSchema']();
+}
+
+export function GetPreferences(arg1) {
+  return window['go']['main']['App']['GetPreferences'](arg1);
+}
+
+export function GetRegistrationStatus() {
+  return window['go']['main']['App']['GetRegistrationStatus']();
+}
+
 export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
 
-export function Initialize(arg1) {
-  return window['go']['main']['App']['Initialize'](arg1);
+export function Initialize(arg1, arg2) {
+  return window['go']['main']['App']['Initialize'](arg1, arg2);
+}
+
+export function IsWorkbenchAllowed() {
+  return window['go']['main']['App']['IsWorkbenchAllowed']();
 }
 
 export function LoadClientID() {
@@ -30,6 +70,46 @@ export function LoadLocaldata(arg1) {
   return window['go']['main']['App']['LoadLocaldata'](arg1);
 }
 
+export function Log(arg1, arg2, arg3) {
+  return window['go']['main']['App']['Log'](arg1, arg2, arg3);
+}
+
+export function MarkPatientAbsent(arg1, arg2) {
+  return window['go']['main']['App']['MarkPatientAbsent'](arg1, arg2);
+}
+
+export function NotifyPatientEvent(arg1) {
+  return window['go']['main']['App']['NotifyPatientEvent'](arg1);
+}
+
+export function PlaySound(arg1, arg2) {
+  return window['go']['main']['App']['PlaySound'](arg1, arg2);
+}
+
+export function PrintSlip(arg1) {
+  return window['go']['main']['App']['PrintSlip'](arg1);
+}
+
+export function QueryLogs(arg1) {
+  return window['go']['main']['App']['QueryLogs'](arg1);
+}
+
+export function RecallAnnouncement() {
+  return window['go']['main']['App']['RecallAnnouncement']();
+}
+
+export function RecommendNextPatients(arg1) {
+  return window['go']['main']['App']['RecommendNextPatients'](arg1);
+}
+
+export function RefreshRegistration() {
+  return window['go']['main']['App']['RefreshRegistration']();
+}
+
+export function ResetPreferences(arg1, arg2) {
+  return window['go']['main']['App']['ResetPreferences'](arg1, arg2);
+}
+
 export function SaveForwardURL(arg1) {
   return window['go']['main']['App']['SaveForwardURL'](arg1);
 }
@@ -37,3 +117,23 @@ export function SaveForwardURL(arg1) {
 export function SaveLocaldata(arg1, arg2, arg3) {
   return window['go']['main']['App']['SaveLocaldata'](arg1, arg2, arg3);
 }
+
+export function SetLogLevel(arg1, arg2) {
+  return window['go']['main']['App']['SetLogLevel'](arg1, arg2);
+}
+
+export function StartLogTail(arg1) {
+  return window['go']['main']['App']['StartLogTail'](arg1);
+}
+
+export function StopLogTail() {
+  return window['go']['main']['App']['StopLogTail']();
+}
+
+export function UpdateConfig(arg1) {
+  return window['go']['main']['App']['UpdateConfig'](arg1);
+}
+
+export function UpdatePreferences(arg1, arg2) {
+  return window['go']['main']['App']['UpdatePreferences'](arg1, arg2);
+}
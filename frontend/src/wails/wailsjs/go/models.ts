@@ -1,3 +1,24 @@
+export namespace announce {
+	
+	export class Call {
+	    number: string;
+	    name: string;
+	    room: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Call(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.number = source["number"];
+	        this.name = source["name"];
+	        this.room = source["room"];
+	    }
+	}
+
+}
+
 export namespace config {
 	
 	export class AppConfig {
@@ -34,6 +55,172 @@ export namespace config {
 	        this.BackgroundColor = source["BackgroundColor"];
 	    }
 	}
+	export class MuteWindow {
+	    Start: string;
+	    End: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MuteWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Start = source["Start"];
+	        this.End = source["End"];
+	    }
+	}
+	export class AudioConfig {
+	    Volume: number;
+	    Sink: string;
+	    Command: string[];
+	    OutputDir: string;
+	    Mute: MuteWindow[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Volume = source["Volume"];
+	        this.Sink = source["Sink"];
+	        this.Command = source["Command"];
+	        this.OutputDir = source["OutputDir"];
+	        this.Mute = this.convertValues(source["Mute"], MuteWindow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiagnosticsConfig {
+	    UploadURL: string;
+	    UploadToken: string;
+	    LogMaxMB: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiagnosticsConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.UploadURL = source["UploadURL"];
+	        this.UploadToken = source["UploadToken"];
+	        this.LogMaxMB = source["LogMaxMB"];
+	    }
+	}
+	export class RemoteConfig {
+	    Enabled: boolean;
+	    PublicKey: string;
+	    IntervalSec: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Enabled = source["Enabled"];
+	        this.PublicKey = source["PublicKey"];
+	        this.IntervalSec = source["IntervalSec"];
+	    }
+	}
+	export class PrinterConfig {
+	    Enabled: boolean;
+	    Output: string;
+	    Address: string;
+	    FilePath: string;
+	    TimeoutMs: number;
+	    Title: string;
+	    Lines: string[];
+	    QRCode: boolean;
+	    Footer: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PrinterConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Enabled = source["Enabled"];
+	        this.Output = source["Output"];
+	        this.Address = source["Address"];
+	        this.FilePath = source["FilePath"];
+	        this.TimeoutMs = source["TimeoutMs"];
+	        this.Title = source["Title"];
+	        this.Lines = source["Lines"];
+	        this.QRCode = source["QRCode"];
+	        this.Footer = source["Footer"];
+	    }
+	}
+	export class DisplayConfig {
+	    Enabled: boolean;
+	    Port: string;
+	    BaudRate: number;
+	    Encoding: string;
+	    Header: string;
+	    Footer: string;
+	    Address: number;
+	    LengthBytes: number;
+	    Checksum: string;
+	    CallTemplate: string;
+	    PassTemplate: string;
+	    EndTemplate: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DisplayConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Enabled = source["Enabled"];
+	        this.Port = source["Port"];
+	        this.BaudRate = source["BaudRate"];
+	        this.Encoding = source["Encoding"];
+	        this.Header = source["Header"];
+	        this.Footer = source["Footer"];
+	        this.Address = source["Address"];
+	        this.LengthBytes = source["LengthBytes"];
+	        this.Checksum = source["Checksum"];
+	        this.CallTemplate = source["CallTemplate"];
+	        this.PassTemplate = source["PassTemplate"];
+	        this.EndTemplate = source["EndTemplate"];
+	    }
+	}
+	export class QueueConfig {
+	    ElderlyPriority: boolean;
+	    ElderlyAge: number;
+	    DisabledPriority: boolean;
+	    RevisitInterval: number;
+	    SkipAbsent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new QueueConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ElderlyPriority = source["ElderlyPriority"];
+	        this.ElderlyAge = source["ElderlyAge"];
+	        this.DisabledPriority = source["DisabledPriority"];
+	        this.RevisitInterval = source["RevisitInterval"];
+	        this.SkipAbsent = source["SkipAbsent"];
+	    }
+	}
 	export class TrayConfig {
 	    Icon: string;
 	    Tooltip: string;
@@ -50,10 +237,41 @@ export namespace config {
 	        this.Title = source["Title"];
 	    }
 	}
+	export class RedactConfig {
+	    Enabled: boolean;
+	    Keys: string[];
+	    NameKeys: string[];
+	    IDCard: boolean;
+	    Phone: boolean;
+	    Patterns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RedactConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Enabled = source["Enabled"];
+	        this.Keys = source["Keys"];
+	        this.NameKeys = source["NameKeys"];
+	        this.IDCard = source["IDCard"];
+	        this.Phone = source["Phone"];
+	        this.Patterns = source["Patterns"];
+	    }
+	}
 	export class LoggingConfig {
 	    Level: string;
 	    Output: string;
 	    FilePath: string;
+	    MaxSizeMB: number;
+	    MaxBackups: number;
+	    MaxAgeDays: number;
+	    MaxTotalMB: number;
+	    Daily: boolean;
+	    Compress: boolean;
+	    QueueSize: number;
+	    Overflow: string;
+	    Redact: RedactConfig;
 	
 	    static createFrom(source: any = {}) {
 	        return new LoggingConfig(source);
@@ -64,12 +282,66 @@ export namespace config {
 	        this.Level = source["Level"];
 	        this.Output = source["Output"];
 	        this.FilePath = source["FilePath"];
+	        this.MaxSizeMB = source["MaxSizeMB"];
+	        this.MaxBackups = source["MaxBackups"];
+	        this.MaxAgeDays = source["MaxAgeDays"];
+	        this.MaxTotalMB = source["MaxTotalMB"];
+	        this.Daily = source["Daily"];
+	        this.Compress = source["Compress"];
+	        this.QueueSize = source["QueueSize"];
+	        this.Overflow = source["Overflow"];
+	        this.Redact = this.convertValues(source["Redact"], RedactConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProcessConfig {
+	    ExePath: string;
+	    Port: number;
+	    Args: string;
+	    StartRetry: number;
+	    RetryDelayMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ExePath = source["ExePath"];
+	        this.Port = source["Port"];
+	        this.Args = source["Args"];
+	        this.StartRetry = source["StartRetry"];
+	        this.RetryDelayMs = source["RetryDelayMs"];
 	    }
 	}
 	export class Config {
 	    App: AppConfig;
+	    Process: ProcessConfig;
 	    Logging: LoggingConfig;
 	    Tray: TrayConfig;
+	    Audio: AudioConfig;
+	    Queue: QueueConfig;
+	    Display: DisplayConfig;
+	    Printer: PrinterConfig;
+	    Remote: RemoteConfig;
+	    Diagnostics: DiagnosticsConfig;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -78,8 +350,15 @@ export namespace config {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.App = this.convertValues(source["App"], AppConfig);
+	        this.Process = this.convertValues(source["Process"], ProcessConfig);
 	        this.Logging = this.convertValues(source["Logging"], LoggingConfig);
 	        this.Tray = this.convertValues(source["Tray"], TrayConfig);
+	        this.Audio = this.convertValues(source["Audio"], AudioConfig);
+	        this.Queue = this.convertValues(source["Queue"], QueueConfig);
+	        this.Display = this.convertValues(source["Display"], DisplayConfig);
+	        this.Printer = this.convertValues(source["Printer"], PrinterConfig);
+	        this.Remote = this.convertValues(source["Remote"], RemoteConfig);
+	        this.Diagnostics = this.convertValues(source["Diagnostics"], DiagnosticsConfig);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -101,6 +380,61 @@ export namespace config {
 		}
 	}
 	
+	
+	
+	
+	export class Options {
+	    Path: string;
+	    Environ: string[];
+	    Args: string[];
+	    Remote: number[];
+	    BaseDir: string;
+	    ExeDir: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Path = source["Path"];
+	        this.Environ = source["Environ"];
+	        this.Args = source["Args"];
+	        this.Remote = source["Remote"];
+	        this.BaseDir = source["BaseDir"];
+	        this.ExeDir = source["ExeDir"];
+	    }
+	}
+	
+	
+	
+	
+	
+
+}
+
+export namespace event {
+	
+	export class Event {
+	    type: string;
+	    number: string;
+	    name: string;
+	    room: string;
+	    visit_id: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Event(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.number = source["number"];
+	        this.name = source["name"];
+	        this.room = source["room"];
+	        this.visit_id = source["visit_id"];
+	    }
+	}
 
 }
 
@@ -125,3 +459,146 @@ export namespace local {
 
 }
 
+export namespace logview {
+	
+	export class Query {
+	    // Go type: time
+	    from: any;
+	    // Go type: time
+	    to: any;
+	    level: string;
+	    contains: string;
+	    attrs: Record<string, string>;
+	    page: number;
+	    pageSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Query(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], null);
+	        this.to = this.convertValues(source["to"], null);
+	        this.level = source["level"];
+	        this.contains = source["contains"];
+	        this.attrs = source["attrs"];
+	        this.page = source["page"];
+	        this.pageSize = source["pageSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace paths {
+	
+	export class Paths {
+	    mode: string;
+	    base: string;
+	    migrated_from?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Paths(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.base = source["base"];
+	        this.migrated_from = source["migrated_from"];
+	    }
+	}
+
+}
+
+export namespace printer {
+	
+	export class Slip {
+	    room: string;
+	    number: string;
+	    name: string;
+	    visit_id: string;
+	    // Go type: time
+	    time: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Slip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.room = source["room"];
+	        this.number = source["number"];
+	        this.name = source["name"];
+	        this.visit_id = source["visit_id"];
+	        this.time = this.convertValues(source["time"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace queue {
+	
+	export class Patient {
+	    appointment_id: string;
+	    name: string;
+	    gender: number;
+	    age: number;
+	    line_num: number;
+	    state: number;
+	    disabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Patient(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.appointment_id = source["appointment_id"];
+	        this.name = source["name"];
+	        this.gender = source["gender"];
+	        this.age = source["age"];
+	        this.line_num = source["line_num"];
+	        this.state = source["state"];
+	        this.disabled = source["disabled"];
+	    }
+	}
+
+}
+
@@ -1,46 +1,150 @@
 package config
 
 import (
-	"os"
-
-	"github.com/pelletier/go-toml/v2"
+	"reflect"
+	"runtime"
 )
 
 // Config 应用配置
+// desc、enum、min、max 标签用于生成 JSON Schema，见 schema.go
+// path 标签表示相对路径的基准：data 为 Options.BaseDir，exe 为 Options.ExeDir
+// secret 标签表示敏感配置，导出诊断信息时隐藏，见 Redacted
 type Config struct {
-	App     AppConfig     `toml:"app"`
-	Logging LoggingConfig `toml:"logging"`
-	Tray    TrayConfig    `toml:"tray"`
+	App         AppConfig         `toml:"app" desc:"应用窗口"`
+	Process     ProcessConfig     `toml:"process" desc:"呼叫进程"`
+	Logging     LoggingConfig     `toml:"logging" desc:"日志"`
+	Tray        TrayConfig        `toml:"tray" desc:"系统托盘"`
+	Audio       AudioConfig       `toml:"audio" desc:"提示音"`
+	Queue       QueueConfig       `toml:"queue" desc:"下一位患者推荐规则"`
+	Display     DisplayConfig     `toml:"display" desc:"门头 LED 屏"`
+	Printer     PrinterConfig     `toml:"printer" desc:"凭条打印机"`
+	Remote      RemoteConfig      `toml:"remote" desc:"集中配置"`
+	Diagnostics DiagnosticsConfig `toml:"diagnostics" desc:"诊断包"`
 }
 
 // AppConfig 应用窗口配置
 type AppConfig struct {
-	Title           string `toml:"title"`
-	Width           int    `toml:"width"`
-	Height          int    `toml:"height"`
-	MinWidth        int    `toml:"min_width"`
-	MinHeight       int    `toml:"min_height"`
-	MaxWidth        int    `toml:"max_width"`
-	MaxHeight       int    `toml:"max_height"`
-	DisableResize   bool   `toml:"disable_resize"`
-	Fullscreen      bool   `toml:"fullscreen"`
-	Frameless       bool   `toml:"frameless"`
-	AlwaysOnTop     bool   `toml:"always_on_top"`
-	BackgroundColor string `toml:"background_color"`
+	Title           string `toml:"title" desc:"窗口标题"`
+	Width           int    `toml:"width" desc:"窗口宽度" min:"1"`
+	Height          int    `toml:"height" desc:"窗口高度" min:"1"`
+	MinWidth        int    `toml:"min_width" desc:"最小宽度" min:"0"`
+	MinHeight       int    `toml:"min_height" desc:"最小高度" min:"0"`
+	MaxWidth        int    `toml:"max_width" desc:"最大宽度，0 表示不限制" min:"0"`
+	MaxHeight       int    `toml:"max_height" desc:"最大高度，0 表示不限制" min:"0"`
+	DisableResize   bool   `toml:"disable_resize" desc:"禁止调整窗口大小"`
+	Fullscreen      bool   `toml:"fullscreen" desc:"全屏启动"`
+	Frameless       bool   `toml:"frameless" desc:"无边框窗口"`
+	AlwaysOnTop     bool   `toml:"always_on_top" desc:"窗口置顶"`
+	BackgroundColor string `toml:"background_color" desc:"窗口背景色"`
+}
+
+// ProcessConfig 本地呼叫进程配置
+type ProcessConfig struct {
+	ExePath      string `toml:"exe_path" desc:"可执行文件路径" path:"exe"`
+	Port         int    `toml:"port" desc:"进程端口" min:"1" max:"65535"`
+	Args         string `toml:"args" desc:"启动参数模板，%d 替换为端口号"`
+	StartRetry   int    `toml:"start_retry" desc:"启动重试次数" min:"1" max:"10"`
+	RetryDelayMs int    `toml:"retry_delay_ms" desc:"重试间隔（毫秒）" min:"0" max:"60000"`
 }
 
 // LoggingConfig 日志配置
 type LoggingConfig struct {
-	Level    string `toml:"level"`
-	Output   string `toml:"output"`
-	FilePath string `toml:"file_path"`
+	Level      string       `toml:"level" desc:"日志级别" enum:"debug,info,warn,error,fatal"`
+	Output     string       `toml:"output" desc:"输出方式" enum:"stdout,file,both,none"`
+	FilePath   string       `toml:"file_path" desc:"日志目录" path:"data"`
+	MaxSizeMB  int          `toml:"max_size_mb" desc:"单个日志文件大小上限（MB）" min:"1" max:"1024"`
+	MaxBackups int          `toml:"max_backups" desc:"每个日志保留的旧文件数量" min:"0" max:"100"`
+	MaxAgeDays int          `toml:"max_age_days" desc:"旧日志文件保留天数" min:"0" max:"365"`
+	MaxTotalMB int          `toml:"max_total_mb" desc:"所有日志文件的总大小上限（MB），0 表示不限" min:"0" max:"102400"`
+	Daily      bool         `toml:"daily" desc:"每天轮转日志文件"`
+	Compress   bool         `toml:"compress" desc:"压缩旧日志文件"`
+	QueueSize  int          `toml:"queue_size" desc:"异步写入队列长度，0 表示同步写入" min:"0" max:"100000"`
+	Overflow   string       `toml:"overflow" desc:"队列满时的处理策略" enum:"block,drop"`
+	Redact     RedactConfig `toml:"redact" desc:"日志脱敏"`
+}
+
+// RedactConfig 日志脱敏配置，对所有输出生效
+type RedactConfig struct {
+	Enabled  bool     `toml:"enabled" desc:"启用日志脱敏"`
+	Keys     []string `toml:"keys" desc:"值整体隐藏的键名"`
+	NameKeys []string `toml:"name_keys" desc:"姓名键名，只保留首尾字"`
+	IDCard   bool     `toml:"id_card" desc:"隐藏身份证号中间部分"`
+	Phone    bool     `toml:"phone" desc:"隐藏手机号中间四位"`
+	Patterns []string `toml:"patterns" desc:"额外的正则表达式，匹配部分整体隐藏"`
 }
 
 // TrayConfig 系统托盘配置
 type TrayConfig struct {
-	Icon    string `toml:"icon"`
-	Tooltip string `toml:"tooltip"`
-	Title   string `toml:"title"`
+	Icon    string `toml:"icon" desc:"托盘图标路径" path:"exe"`
+	Tooltip string `toml:"tooltip" desc:"托盘提示文字"`
+	Title   string `toml:"title" desc:"托盘标题"`
+}
+
+// AudioConfig 提示音配置
+type AudioConfig struct {
+	Volume    int          `toml:"volume" desc:"提示音音量" min:"0" max:"100"`
+	Sink      string       `toml:"sink" desc:"输出方式" enum:"device,file,none"`
+	Command   []string     `toml:"command" desc:"播放命令，从标准输入读取 WAV，默认按操作系统选择"`
+	OutputDir string       `toml:"output_dir" desc:"sink 为 file 时的输出目录" path:"data"`
+	Mute      []MuteWindow `toml:"mute" desc:"静音时段"`
+}
+
+// MuteWindow 静音时段
+type MuteWindow struct {
+	Start string `toml:"start" desc:"开始时间 HH:MM"`
+	End   string `toml:"end" desc:"结束时间 HH:MM"`
+}
+
+// QueueConfig 下一位患者推荐规则配置
+type QueueConfig struct {
+	ElderlyPriority  bool `toml:"elderly_priority" desc:"老年人优先"`
+	ElderlyAge       int  `toml:"elderly_age" desc:"老年人年龄下限" min:"0"`
+	DisabledPriority bool `toml:"disabled_priority" desc:"残疾人优先"`
+	RevisitInterval  int  `toml:"revisit_interval" desc:"每叫 N 位候诊患者插入一位复诊患者，0 表示按排队顺序" min:"0"`
+	SkipAbsent       bool `toml:"skip_absent" desc:"跳过未到患者"`
+}
+
+// DisplayConfig 门头 LED 屏配置
+type DisplayConfig struct {
+	Enabled      bool   `toml:"enabled" desc:"启用门头屏"`
+	Port         string `toml:"port" desc:"串口"`
+	BaudRate     int    `toml:"baud_rate" desc:"波特率" enum:"1200,2400,4800,9600,19200,38400,57600,115200,230400"`
+	Encoding     string `toml:"encoding" desc:"文字编码" enum:"gb2312,gbk,utf8,utf-8"`
+	Header       string `toml:"header" desc:"帧头（十六进制）"`
+	Footer       string `toml:"footer" desc:"帧尾（十六进制）"`
+	Address      int    `toml:"address" desc:"屏地址，-1 表示帧中不含地址" min:"-1" max:"255"`
+	LengthBytes  int    `toml:"length_bytes" desc:"长度字段字节数" min:"0" max:"2"`
+	Checksum     string `toml:"checksum" desc:"校验方式" enum:"none,sum8,xor,crc16"`
+	CallTemplate string `toml:"call_template" desc:"呼叫显示模板"`
+	PassTemplate string `toml:"pass_template" desc:"过号显示模板"`
+	EndTemplate  string `toml:"end_template" desc:"结诊显示模板"`
+}
+
+// PrinterConfig 凭条打印机配置
+type PrinterConfig struct {
+	Enabled   bool     `toml:"enabled" desc:"启用凭条打印"`
+	Output    string   `toml:"output" desc:"输出方式" enum:"tcp,file"`
+	Address   string   `toml:"address" desc:"打印机地址 host:port"`
+	FilePath  string   `toml:"file_path" desc:"output 为 file 时的输出文件" path:"data"`
+	TimeoutMs int      `toml:"timeout_ms" desc:"连接和写入超时（毫秒），0 使用默认值 3000" min:"0"`
+	Title     string   `toml:"title" desc:"凭条标题"`
+	Lines     []string `toml:"lines" desc:"凭条内容模板"`
+	QRCode    bool     `toml:"qr_code" desc:"打印二维码"`
+	Footer    string   `toml:"footer" desc:"凭条页脚"`
+}
+
+// RemoteConfig 服务端集中配置
+type RemoteConfig struct {
+	Enabled     bool   `toml:"enabled" desc:"启用服务端下发配置"`
+	PublicKey   string `toml:"public_key" desc:"配置签名公钥（Ed25519，Base64）"`
+	IntervalSec int    `toml:"interval_sec" desc:"拉取间隔（秒）" min:"30" max:"86400"`
+}
+
+// DiagnosticsConfig 诊断包配置
+type DiagnosticsConfig struct {
+	UploadURL   string `toml:"upload_url" desc:"诊断包上传地址，为空表示不上传"`
+	UploadToken string `toml:"upload_token" desc:"上传认证令牌" secret:"true"`
+	LogMaxMB    int    `toml:"log_max_mb" desc:"每个日志文件最多打包的大小（MB）" min:"1" max:"100"`
 }
 
 // Default 返回默认配置
@@ -49,7 +153,7 @@ func Default() *Config {
 		App: AppConfig{
 			Title:           "呼叫客户端",
 			Width:           430,
-			Height:          800,
+			Height:          700,
 			MinWidth:        430,
 			MinHeight:       550,
 			MaxWidth:        750,
@@ -60,31 +164,114 @@ func Default() *Config {
 			AlwaysOnTop:     false,
 			BackgroundColor: "#FFFFFF",
 		},
+		Process: ProcessConfig{
+			ExePath:      "root/process/suwei_caller_local.exe",
+			Port:         21999,
+			Args:         "--port=%d",
+			StartRetry:   3,
+			RetryDelayMs: 1000,
+		},
 		Logging: LoggingConfig{
-			Level:  "debug",
-			Output: "stdout",
+			Level:      "debug",
+			Output:     "both",
+			FilePath:   "root/logs",
+			MaxSizeMB:  50,
+			MaxBackups: 30,
+			MaxAgeDays: 10,
+			MaxTotalMB: 1024,
+			Daily:      true,
+			Compress:   true,
+			QueueSize:  1000,
+			Overflow:   "drop",
+			Redact: RedactConfig{
+				Enabled:  true,
+				Keys:     []string{"password", "token", "secret", "authorization", "id_card", "id_number", "phone", "mobile"},
+				NameKeys: []string{"patient_name", "pat_name", "name"},
+				IDCard:   true,
+				Phone:    true,
+			},
 		},
 		Tray: TrayConfig{
 			Icon:    "./root/icon.ico",
 			Tooltip: "呼叫客户端",
 			Title:   "呼叫客户端",
 		},
+		Audio: AudioConfig{
+			Volume:  80,
+			Sink:    "device",
+			Command: defaultAudioCommand(runtime.GOOS),
+		},
+		Queue: QueueConfig{
+			ElderlyPriority:  true,
+			ElderlyAge:       65,
+			DisabledPriority: true,
+			RevisitInterval:  3,
+			SkipAbsent:       true,
+		},
+		Display: DisplayConfig{
+			Enabled:      false,
+			Port:         "COM1",
+			BaudRate:     9600,
+			Encoding:     "gb2312",
+			Header:       "AA",
+			Footer:       "55",
+			Address:      1,
+			LengthBytes:  1,
+			Checksum:     "sum8",
+			CallTemplate: "{number}号 {name}",
+			PassTemplate: "{number}号 过号",
+			EndTemplate:  "{room} 请稍候",
+		},
+		Printer: PrinterConfig{
+			Enabled:   false,
+			Output:    "tcp",
+			Address:   "192.168.1.100:9100",
+			FilePath:  "root/print/slip.bin",
+			TimeoutMs: 3000,
+			Title:     "就诊凭条",
+			Lines:     []string{"诊室: {room}", "排队号: {number}", "姓名: {name}", "时间: {time}"},
+			QRCode:    true,
+			Footer:    "请在候诊区等候叫号",
+		},
+		Remote: RemoteConfig{
+			Enabled:     false,
+			IntervalSec: 300,
+		},
+		Diagnostics: DiagnosticsConfig{
+			LogMaxMB: 5,
+		},
 	}
 }
 
-// Load 从文件加载配置
+// Load 从文件加载配置并校验，不读取环境变量和命令行参数
 func Load(path string) (*Config, error) {
-	cfg := Default()
-
-	data, err := os.ReadFile(path)
+	result, err := LoadLayered(Options{Path: path})
 	if err != nil {
-		// 配置文件不存在，使用默认配置
-		return cfg, nil
+		return nil, err
 	}
+	return result.Config, nil
+}
 
-	if err := toml.Unmarshal(data, cfg); err != nil {
-		return nil, err
+// Redacted 返回隐藏了敏感配置项的副本，用于导出诊断信息
+func (c *Config) Redacted() *Config {
+	cp := *c
+	for _, f := range collectFields(&cp) {
+		if f.secret && f.value.Kind() == reflect.String && f.value.String() != "" {
+			f.value.SetString(redactedValue)
+		}
 	}
+	return &cp
+}
 
-	return cfg, nil
+// defaultAudioCommand 各操作系统自带的播放命令，WAV 数据通过标准输入传入
+func defaultAudioCommand(goos string) []string {
+	switch goos {
+	case "windows":
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command",
+			"$m = New-Object IO.MemoryStream; [Console]::OpenStandardInput().CopyTo($m); $m.Position = 0; (New-Object Media.SoundPlayer $m).PlaySync()"}
+	case "darwin":
+		// afplay 不支持从标准输入读取，先写入临时文件
+		return []string{"sh", "-c", `f=$(mktemp) && cat > "$f" && afplay "$f"; rm -f "$f"`}
+	}
+	return []string{"aplay", "-q", "-"}
 }
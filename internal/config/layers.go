@@ -0,0 +1,443 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// EnvPrefix 环境变量前缀，例如 SWCALL_APP_WIDTH 对应 app.width
+const EnvPrefix = "SWCALL_"
+
+// Source 配置值来源
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceRemote  Source = "remote"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Sources 每个配置项的来源，键为字段路径，例如 app.width
+type Sources map[string]Source
+
+// Options 分层加载参数
+type Options struct {
+	Path    string   // 默认配置文件路径，可被 --config 覆盖
+	Environ []string // 环境变量，通常为 os.Environ()
+	Args    []string // 命令行参数，不含程序名
+	Remote  []byte   // 服务端下发的 TOML 配置，叠加在配置文件之上
+	BaseDir string   // 数据类相对路径（日志、输出文件）的基准目录，为空时相对于当前工作目录
+	ExeDir  string   // 随程序发布的资源（图标、呼叫进程）的基准目录，为空时相对于当前工作目录
+}
+
+// Result 分层加载结果
+type Result struct {
+	Config      *Config
+	Raw         *Config // 解析相对路径前的配置，设置页按此读取和写回，避免写入本机绝对路径
+	Sources     Sources
+	Path        string   // 实际使用的配置文件路径
+	PrintConfig bool     // 是否通过 --print-config 请求打印生效配置
+	Unknown     []string // 配置文件中无法识别的配置项，已忽略
+	UnknownArgs []string // 无法识别的命令行参数，已忽略
+}
+
+// field 可覆盖的配置项
+type field struct {
+	path   string
+	value  reflect.Value
+	secret bool
+}
+
+// redactedValue 敏感配置项导出时的替代值
+const redactedValue = "******"
+
+// overrideFlag 记录命令行覆盖值，布尔项支持 --app.fullscreen 简写
+type overrideFlag struct {
+	path      string
+	overrides map[string]string
+	isBool    bool
+}
+
+func (f *overrideFlag) String() string   { return "" }
+func (f *overrideFlag) IsBoolFlag() bool { return f.isBool }
+func (f *overrideFlag) Set(value string) error {
+	f.overrides[f.path] = value
+	return nil
+}
+
+// LoadLayered 依次叠加默认值、配置文件、远程配置、环境变量和命令行参数，最后统一校验
+func LoadLayered(opts Options) (*Result, error) {
+	cfg := Default()
+	fields := collectFields(cfg)
+
+	result := &Result{
+		Config:  cfg,
+		Sources: make(Sources, len(fields)),
+		Path:    opts.Path,
+	}
+	for _, f := range fields {
+		result.Sources[f.path] = SourceDefault
+	}
+
+	// 先解析命令行，获取 --config，但命令行的值最后才应用
+	flags := flag.NewFlagSet("sw_call", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&result.Path, "config", opts.Path, "配置文件路径")
+	flags.BoolVar(&result.PrintConfig, "print-config", false, "打印生效的配置及来源后退出")
+	overrides := make(map[string]string)
+	for _, f := range fields {
+		flags.Var(&overrideFlag{
+			path:      f.path,
+			overrides: overrides,
+			isBool:    f.value.Kind() == reflect.Bool,
+		}, f.path, "覆盖配置项 "+f.path)
+	}
+	// 未知参数（例如系统或启动器附加的参数）与未知配置项一样只记录警告
+	args, unknown := splitArgs(flags, opts.Args)
+	result.UnknownArgs = unknown
+	if err := flags.Parse(args); err != nil {
+		return nil, fmt.Errorf("解析命令行参数失败: %w", err)
+	}
+
+	if err := applyFile(result, fields); err != nil {
+		return nil, err
+	}
+	if err := applyRemote(result, fields, opts.Remote); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	env := environMap(opts.Environ)
+	for _, f := range fields {
+		value, ok := env[EnvName(f.path)]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, value); err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Err: fmt.Errorf("环境变量 %s: %w", EnvName(f.path), err)})
+			continue
+		}
+		result.Sources[f.path] = SourceEnv
+	}
+
+	for _, f := range fields {
+		value, ok := overrides[f.path]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.value, value); err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Err: fmt.Errorf("命令行参数 --%s: %w", f.path, err)})
+			continue
+		}
+		result.Sources[f.path] = SourceFlag
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	raw := *cfg
+	result.Raw = &raw
+	opts.resolvePaths(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolvePaths 按 BaseDir 和 ExeDir 解析配置中的相对路径
+func (o Options) resolvePaths(cfg *Config) {
+	resolvePaths(cfg, map[string]string{"data": o.BaseDir, "exe": o.ExeDir})
+}
+
+// resolvePaths 将带 path 标签的相对路径解析为基于对应目录的路径
+func resolvePaths(cfg *Config, bases map[string]string) {
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := v.Field(i)
+			if f.Kind() == reflect.Struct {
+				walk(f)
+				continue
+			}
+			base := bases[t.Field(i).Tag.Get("path")]
+			if base == "" || f.Kind() != reflect.String {
+				continue
+			}
+			if p := f.String(); p != "" && !filepath.IsAbs(p) {
+				f.SetString(filepath.Join(base, p))
+			}
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem())
+}
+
+// applyFile 读取配置文件并记录文件中出现的配置项
+func applyFile(result *Result, fields []field) error {
+	data, err := os.ReadFile(result.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrConfigNotFound, result.Path)
+		}
+		return err
+	}
+
+	if err := toml.Unmarshal(data, result.Config); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrConfigParseFailed, result.Path, err)
+	}
+
+	// 严格模式再解析一次，只用于找出拼写错误或已废弃的配置项
+	strict := toml.NewDecoder(bytes.NewReader(data))
+	strict.DisallowUnknownFields()
+	var missing *toml.StrictMissingError
+	if err := strict.Decode(Default()); errors.As(err, &missing) {
+		for _, e := range missing.Errors {
+			result.Unknown = append(result.Unknown, strings.Join(e.Key(), "."))
+		}
+	}
+
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrConfigParseFailed, result.Path, err)
+	}
+	for _, f := range fields {
+		if lookupPath(doc, f.path) {
+			result.Sources[f.path] = SourceFile
+		}
+	}
+	return nil
+}
+
+// applyRemote 叠加服务端下发的配置，远程配置不能修改 [remote] 节自身
+func applyRemote(result *Result, fields []field, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: 远程配置: %v", ErrConfigParseFailed, err)
+	}
+	if _, ok := doc["remote"]; ok {
+		slog.Warn("远程配置不能修改 [remote] 节，已忽略")
+		delete(doc, "remote")
+	}
+
+	// 去掉 [remote] 后重新编码，再按结构体解析
+	clean, err := toml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("%w: 远程配置: %v", ErrConfigParseFailed, err)
+	}
+	if err := toml.Unmarshal(clean, result.Config); err != nil {
+		return fmt.Errorf("%w: 远程配置: %v", ErrConfigParseFailed, err)
+	}
+
+	for _, f := range fields {
+		if lookupPath(doc, f.path) {
+			result.Sources[f.path] = SourceRemote
+		}
+	}
+	return nil
+}
+
+// Overridden 返回来源为 source 的配置项，按字段顺序排列
+func (r *Result) Overridden(source Source) []string {
+	var paths []string
+	for _, f := range collectFields(r.Config) {
+		if r.Sources[f.path] == source {
+			paths = append(paths, f.path)
+		}
+	}
+	return paths
+}
+
+// WarnUnknown 记录配置文件中无法识别的配置项和命令行参数
+func (r *Result) WarnUnknown() {
+	for _, key := range r.Unknown {
+		slog.Warn("配置文件包含未知配置项，已忽略", "key", key, "path", r.Path)
+	}
+	for _, arg := range r.UnknownArgs {
+		slog.Warn("未知命令行参数，已忽略", "arg", arg)
+	}
+}
+
+// splitArgs 拆分出未定义的命令行参数，其余参数交给 flags 解析
+// 未知参数不带 = 时，紧随其后的非参数值视为它的值一并忽略
+func splitArgs(flags *flag.FlagSet, args []string) (known, unknown []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			return append(known, args[i:]...), unknown
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		hasNext := !hasValue && i+1 < len(args)
+
+		f := flags.Lookup(name)
+		if f == nil {
+			if hasNext && !strings.HasPrefix(args[i+1], "-") {
+				arg += " " + args[i+1]
+				i++
+			}
+			unknown = append(unknown, arg)
+			continue
+		}
+		known = append(known, arg)
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); hasNext && !(ok && b.IsBoolFlag()) {
+			i++
+			known = append(known, args[i])
+		}
+	}
+	return known, unknown
+}
+
+// EnvName 返回配置项对应的环境变量名
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// Describe 返回生效配置及每项来源，用于 --print-config
+func (r *Result) Describe() string {
+	fields := collectFields(r.Config)
+
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.path))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 配置文件: %s\n", r.Path)
+	for _, f := range fields {
+		value := formatValue(f.value)
+		if f.secret && !f.value.IsZero() {
+			value = strconv.Quote(redactedValue)
+		}
+		fmt.Fprintf(&b, "%-*s = %-30s # %s\n", width, f.path, value, r.Sources[f.path])
+	}
+	return b.String()
+}
+
+// collectFields 按 toml 标签遍历所有标量配置项，结构体切片（如 audio.mute）不支持覆盖
+func collectFields(cfg *Config) []field {
+	var fields []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			tag := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			path := tag
+			if prefix != "" {
+				path = prefix + "." + tag
+			}
+
+			fv := v.Field(i)
+			secret := t.Field(i).Tag.Get("secret") == "true"
+			switch fv.Kind() {
+			case reflect.Struct:
+				walk(path, fv)
+			case reflect.Slice:
+				if fv.Type().Elem().Kind() == reflect.String {
+					fields = append(fields, field{path: path, value: fv, secret: secret})
+				}
+			case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+				fields = append(fields, field{path: path, value: fv, secret: secret})
+			}
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return fields
+}
+
+// setValue 将字符串解析为字段类型并赋值
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q 不是布尔值", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q 不是整数", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q 不是数字", raw)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		// 字符串列表用逗号分隔
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("不支持的类型 %s", v.Kind())
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = strconv.Quote(v.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// lookupPath 判断 TOML 文档中是否存在指定路径
+func lookupPath(doc map[string]any, path string) bool {
+	parts := strings.Split(path, ".")
+	var current any = doc
+	for _, part := range parts {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		if current, ok = m[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(key, EnvPrefix) {
+			env[key] = value
+		}
+	}
+	return env
+}
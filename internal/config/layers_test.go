@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadLayered(t *testing.T) {
+	path := writeConfig(t, `
+[app]
+width = 500
+height = 600
+
+[tray]
+icon = ""
+
+[logging]
+level = "info"
+`)
+
+	result, err := LoadLayered(Options{
+		Path: path,
+		Environ: []string{
+			"SWCALL_APP_HEIGHT=700",
+			"SWCALL_LOGGING_LEVEL=warn",
+			"SWCALL_AUDIO_COMMAND=paplay, --volume=65536",
+			"SWCALL_DIAGNOSTICS_UPLOAD_TOKEN=s3cret",
+			"HOME=/root",
+		},
+		Args: []string{"--logging.level=error", "--app.always_on_top"},
+	})
+	assert.NoError(t, err)
+
+	cfg := result.Config
+	assert.Equal(t, 500, cfg.App.Width)
+	assert.Equal(t, 700, cfg.App.Height)
+	assert.Equal(t, "error", cfg.Logging.Level)
+	assert.True(t, cfg.App.AlwaysOnTop)
+	assert.Equal(t, []string{"paplay", "--volume=65536"}, cfg.Audio.Command)
+
+	assert.Equal(t, SourceFile, result.Sources["app.width"])
+	assert.Equal(t, SourceEnv, result.Sources["app.height"])
+	assert.Equal(t, SourceFlag, result.Sources["logging.level"])
+	assert.Equal(t, SourceFlag, result.Sources["app.always_on_top"])
+	assert.Equal(t, SourceDefault, result.Sources["app.title"])
+
+	described := result.Describe()
+	assert.Contains(t, described, "app.height")
+	assert.Contains(t, described, "# env")
+	assert.NotContains(t, described, "s3cret", "敏感配置项不输出")
+
+	redacted := cfg.Redacted()
+	assert.Equal(t, "******", redacted.Diagnostics.UploadToken)
+	assert.Equal(t, "s3cret", cfg.Diagnostics.UploadToken, "不修改原配置")
+	assert.Equal(t, cfg.App, redacted.App)
+}
+
+func TestLoadLayeredConfigFlag(t *testing.T) {
+	path := writeConfig(t, "[app]\ntitle = \"诊室一\"\n[tray]\nicon = \"\"\n")
+
+	result, err := LoadLayered(Options{
+		Path: "missing.toml",
+		Args: []string{"--config", path, "--print-config"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, path, result.Path)
+	assert.True(t, result.PrintConfig)
+	assert.Equal(t, "诊室一", result.Config.App.Title)
+}
+
+func TestLoadLayeredErrors(t *testing.T) {
+	path := writeConfig(t, "[tray]\nicon = \"\"\n")
+
+	_, err := LoadLayered(Options{Path: path, Environ: []string{"SWCALL_APP_WIDTH=wide"}})
+	assert.ErrorContains(t, err, "app.width")
+	assert.ErrorContains(t, err, "SWCALL_APP_WIDTH")
+
+	_, err = LoadLayered(Options{Path: path, Args: []string{"--app.width", "wide"}})
+	assert.ErrorContains(t, err, "--app.width")
+
+	// 覆盖后的值同样需要通过校验
+	_, err = LoadLayered(Options{Path: path, Args: []string{"--audio.volume=300"}})
+	assert.ErrorContains(t, err, "audio.volume")
+}
+
+func TestLoadLayeredUnknownKeys(t *testing.T) {
+	path := writeConfig(t, `
+[process]
+port = 22000
+retry_delay = 500
+
+[tray]
+icon = ""
+tooltp = "一诊室"
+`)
+	result, err := LoadLayered(Options{Path: path})
+	assert.NoError(t, err)
+	assert.Equal(t, 22000, result.Config.Process.Port)
+	assert.Equal(t, "呼叫客户端", result.Config.Tray.Tooltip)
+	assert.ElementsMatch(t, []string{"process.retry_delay", "tray.tooltp"}, result.Unknown)
+}
+
+func TestLoadLayeredUnknownArgs(t *testing.T) {
+	path := writeConfig(t, "[tray]\nicon = \"\"\n")
+
+	result, err := LoadLayered(Options{
+		Path: path,
+		Args: []string{"-psn_0_12345", "--unknown.key=1", "--app.width", "500", "--launcher", "steam", "--app.always_on_top", "--app.title=诊室"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 500, result.Config.App.Width)
+	assert.True(t, result.Config.App.AlwaysOnTop)
+	assert.Equal(t, "诊室", result.Config.App.Title)
+	assert.Equal(t, []string{"-psn_0_12345", "--unknown.key=1", "--launcher steam"}, result.UnknownArgs)
+}
+
+func TestLoadLayeredRemote(t *testing.T) {
+	path := writeConfig(t, "[app]\nwidth = 500\nheight = 600\n\n[tray]\nicon = \"\"\n")
+	remote := []byte("[app]\nwidth = 520\ntitle = \"一诊室\"\n\n[remote]\nenabled = true\n")
+
+	result, err := LoadLayered(Options{
+		Path:    path,
+		Environ: []string{"SWCALL_APP_TITLE=环境变量"},
+		Remote:  remote,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 520, result.Config.App.Width)
+	assert.Equal(t, 600, result.Config.App.Height)
+	assert.Equal(t, "环境变量", result.Config.App.Title)
+	assert.False(t, result.Config.Remote.Enabled, "远程配置不能修改 [remote]")
+	assert.Equal(t, []string{"app.width"}, result.Overridden(SourceRemote))
+
+	_, err = LoadLayered(Options{Path: path, Remote: []byte("[app")})
+	assert.ErrorIs(t, err, ErrConfigParseFailed)
+}
+
+func TestLoadLayeredResolvesPaths(t *testing.T) {
+	exeDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(exeDir, "icon.ico"), nil, 0644))
+	path := writeConfig(t, "[tray]\nicon = \"icon.ico\"\n[logging]\nfile_path = \"/var/log/sw_call\"\n")
+
+	result, err := LoadLayered(Options{Path: path, BaseDir: "/data", ExeDir: exeDir})
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(exeDir, "icon.ico"), result.Config.Tray.Icon)
+	assert.Equal(t, "/var/log/sw_call", result.Config.Logging.FilePath, "绝对路径不变")
+	assert.Equal(t, filepath.Join("/data", "root", "print", "slip.bin"), result.Config.Printer.FilePath)
+	assert.Equal(t, filepath.Join(exeDir, "root", "process", "suwei_caller_local.exe"), result.Config.Process.ExePath)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "SWCALL_APP_WIDTH", EnvName("app.width"))
+	assert.Equal(t, "SWCALL_LOGGING_LEVEL", EnvName("logging.level"))
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Schema 配置的 JSON Schema（draft 2020-12 子集），供设置页按字段生成表单
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty"`
+	WriteOnly   bool               `json:"writeOnly,omitempty"` // 敏感配置项，界面不回显
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Order       []string           `json:"x-order,omitempty"` // 属性在配置文件中的顺序
+}
+
+// JSONSchema 根据 Config 的结构体标签生成 JSON Schema，默认值取自 Default
+// 不在 EditablePaths 中的配置项标记为 readOnly
+func JSONSchema() *Schema {
+	root := objectSchema("", reflect.ValueOf(Default()).Elem())
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "呼叫客户端配置"
+	return root
+}
+
+// Lookup 按 app.width 形式的路径查找字段定义
+func (s *Schema) Lookup(path string) *Schema {
+	current := s
+	for _, name := range strings.Split(path, ".") {
+		if current == nil || current.Properties == nil {
+			return nil
+		}
+		current = current.Properties[name]
+	}
+	return current
+}
+
+// Check 按类型、枚举和取值范围校验单个值，值来自前端 JSON
+func (s *Schema) Check(value any) error {
+	switch s.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("必须为字符串")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("必须为布尔值")
+		}
+	case "integer":
+		n, ok := toFloat(value)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("必须为整数")
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%v 小于最小值 %v", n, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%v 大于最大值 %v", n, *s.Maximum)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("必须为数组")
+		}
+		for i, item := range items {
+			if err := s.Items.Check(item); err != nil {
+				return fmt.Errorf("第 %d 项%w", i+1, err)
+			}
+		}
+	case "object":
+		if _, ok := value.(map[string]any); !ok {
+			return fmt.Errorf("必须为对象")
+		}
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equalJSON(e, value) }) {
+		return fmt.Errorf("%v 无效，可选值: %v", value, s.Enum)
+	}
+	return nil
+}
+
+// objectSchema 遍历结构体字段生成 object 定义
+func objectSchema(prefix string, v reflect.Value) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("toml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		field := fieldSchema(path, sf, v.Field(i))
+		field.Description = sf.Tag.Get("desc")
+		s.Properties[name] = field
+		s.Order = append(s.Order, name)
+	}
+	return s
+}
+
+// fieldSchema 生成单个字段的定义
+func fieldSchema(path string, sf reflect.StructField, v reflect.Value) *Schema {
+	switch v.Kind() {
+	case reflect.Struct:
+		return objectSchema(path, v)
+	case reflect.Slice:
+		s := &Schema{Type: "array", ReadOnly: !isEditable(path)}
+		elem := reflect.New(sf.Type.Elem()).Elem()
+		if elem.Kind() == reflect.Struct {
+			s.Items = objectSchema(path, elem)
+		} else {
+			s.Items = &Schema{Type: jsonType(elem.Kind())}
+		}
+		if v.Len() > 0 {
+			s.Default = v.Interface()
+		}
+		return s
+	}
+
+	s := &Schema{
+		Type:      jsonType(v.Kind()),
+		Default:   v.Interface(),
+		ReadOnly:  !isEditable(path),
+		WriteOnly: sf.Tag.Get("secret") == "true",
+	}
+	if min, ok := sf.Tag.Lookup("min"); ok {
+		s.Minimum = parseBound(min)
+	}
+	if max, ok := sf.Tag.Lookup("max"); ok {
+		s.Maximum = parseBound(max)
+	}
+	if enum, ok := sf.Tag.Lookup("enum"); ok {
+		for _, item := range strings.Split(enum, ",") {
+			if v.Kind() == reflect.Int {
+				n, _ := strconv.Atoi(item)
+				s.Enum = append(s.Enum, n)
+			} else {
+				s.Enum = append(s.Enum, item)
+			}
+		}
+	}
+	return s
+}
+
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Struct:
+		return "object"
+	}
+	return "string"
+}
+
+func parseBound(s string) *float64 {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(fmt.Sprintf("config: 无效的取值范围标签 %q", s))
+	}
+	return &n
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equalJSON 比较枚举值，数字统一按 float64 比较
+func equalJSON(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	return a == b
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"app", "process", "logging", "tray", "audio", "queue", "display", "printer", "remote", "diagnostics"}, schema.Order)
+
+	width := schema.Lookup("app.width")
+	assert.Equal(t, "integer", width.Type)
+	assert.Equal(t, 430, width.Default)
+	assert.Equal(t, 1.0, *width.Minimum)
+	assert.False(t, width.ReadOnly)
+
+	level := schema.Lookup("logging.level")
+	assert.Equal(t, []any{"debug", "info", "warn", "error", "fatal"}, level.Enum)
+	assert.True(t, level.ReadOnly)
+
+	assert.Contains(t, schema.Lookup("display.baud_rate").Enum, 9600)
+	assert.Equal(t, "object", schema.Lookup("audio.mute").Items.Type)
+	assert.Equal(t, "string", schema.Lookup("printer.lines").Items.Type)
+	assert.Nil(t, schema.Lookup("app.unknown"))
+	assert.True(t, schema.Lookup("diagnostics.upload_token").WriteOnly)
+
+	// 所有字段都要有说明
+	var walk func(path string, s *Schema)
+	walk = func(path string, s *Schema) {
+		for name, p := range s.Properties {
+			assert.NotEmpty(t, p.Description, path+name)
+			walk(path+name+".", p)
+		}
+		if s.Items != nil {
+			walk(path, s.Items)
+		}
+	}
+	walk("", schema)
+
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"$schema":"https://json-schema.org/draft/2020-12/schema"`)
+}
+
+func TestSchemaCheck(t *testing.T) {
+	schema := JSONSchema()
+
+	assert.NoError(t, schema.Lookup("app.width").Check(float64(500)))
+	assert.ErrorContains(t, schema.Lookup("app.width").Check(float64(0)), "最小值")
+	assert.ErrorContains(t, schema.Lookup("app.width").Check(1.5), "整数")
+	assert.ErrorContains(t, schema.Lookup("app.title").Check(12.0), "字符串")
+	assert.ErrorContains(t, schema.Lookup("process.port").Check(70000.0), "最大值")
+
+	assert.NoError(t, schema.Lookup("display.baud_rate").Check(float64(9600)))
+	assert.ErrorContains(t, schema.Lookup("display.baud_rate").Check(float64(1000)), "可选值")
+	assert.ErrorContains(t, schema.Lookup("logging.output").Check("syslog"), "可选值")
+
+	assert.NoError(t, schema.Lookup("printer.lines").Check([]any{"a", "b"}))
+	assert.ErrorContains(t, schema.Lookup("printer.lines").Check([]any{"a", 1.0}), "第 2 项")
+}
+
+func TestApplyEditsUsesSchema(t *testing.T) {
+	cfg := validConfig()
+
+	_, err := cfg.ApplyEdits(map[string]any{"app.width": float64(0), "app.title": true}, Options{})
+	assert.ErrorContains(t, err, "app.width: 0 小于最小值 1")
+	assert.ErrorContains(t, err, "app.title: 必须为字符串")
+
+	next, err := cfg.ApplyEdits(map[string]any{"app.always_on_top": true}, Options{})
+	assert.NoError(t, err)
+	assert.True(t, next.App.AlwaysOnTop)
+	assert.False(t, cfg.App.AlwaysOnTop)
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogLevels 合法的日志级别
+var LogLevels = []string{"debug", "info", "warn", "error", "fatal"}
+
+// FieldError 带字段路径的配置错误
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// validator 收集所有校验错误
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(path string, err error) {
+	v.errs = append(v.errs, &FieldError{Path: path, Err: err})
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.add(path, fmt.Errorf(format, args...))
+}
+
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.addf(path, "%q 无效，可选值: %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func (v *validator) between(path string, value, min, max int) {
+	if value < min || value > max {
+		v.addf(path, "%d 超出范围 [%d, %d]", value, min, max)
+	}
+}
+
+func (v *validator) hex(path, value string) {
+	if _, err := hex.DecodeString(strings.ReplaceAll(value, " ", "")); err != nil {
+		v.addf(path, "%q 不是合法的十六进制", value)
+	}
+}
+
+// Validate 校验所有配置项，返回所有问题合并后的错误
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	c.App.validate(v)
+	c.Process.validate(v)
+	c.Logging.validate(v)
+	c.Tray.validate(v)
+	c.Audio.validate(v)
+	c.Queue.validate(v)
+	c.Display.validate(v)
+	c.Printer.validate(v)
+	c.Remote.validate(v)
+	c.Diagnostics.validate(v)
+
+	return errors.Join(v.errs...)
+}
+
+func (a *AppConfig) validate(v *validator) {
+	if a.Width <= 0 {
+		v.addf("app.width", "必须大于 0")
+	}
+	if a.Height <= 0 {
+		v.addf("app.height", "必须大于 0")
+	}
+
+	// Max 为 0 表示不限制，窗口尺寸需在最小和最大值之间
+	checkRange := func(name string, size, min, max int) {
+		if min < 0 {
+			v.addf("app.min_"+name, "不能为负数")
+		}
+		if max < 0 {
+			v.addf("app.max_"+name, "不能为负数")
+		}
+		if max > 0 && min > max {
+			v.addf("app.min_"+name, "%d 大于 max_%s %d", min, name, max)
+			return
+		}
+		if size > 0 && size < min {
+			v.addf("app."+name, "%d 小于 min_%s %d", size, name, min)
+		}
+		if size > 0 && max > 0 && size > max {
+			v.addf("app."+name, "%d 大于 max_%s %d", size, name, max)
+		}
+	}
+	checkRange("width", a.Width, a.MinWidth, a.MaxWidth)
+	checkRange("height", a.Height, a.MinHeight, a.MaxHeight)
+}
+
+func (p *ProcessConfig) validate(v *validator) {
+	if strings.TrimSpace(p.ExePath) == "" {
+		v.add("process.exe_path", ErrEmptyExePath)
+	}
+	if p.Port < 1 || p.Port > 65535 {
+		v.add("process.port", fmt.Errorf("%w: %d", ErrInvalidPort, p.Port))
+	}
+	// 启动参数中的 %d 替换为端口号，可以为空或不传端口，但最多一个 %d，且不能有其他占位符
+	if n, ok := countPortVerbs(p.Args); !ok || n > 1 {
+		v.addf("process.args", "%q 最多只能包含一个 %%d 占位符，字面量 %% 需写成 %%%%", p.Args)
+	}
+	v.between("process.start_retry", p.StartRetry, 1, 10)
+	v.between("process.retry_delay_ms", p.RetryDelayMs, 0, 60000)
+}
+
+func (l *LoggingConfig) validate(v *validator) {
+	if !slices.Contains(LogLevels, strings.ToLower(l.Level)) {
+		v.add("logging.level", fmt.Errorf("%w: %q", ErrInvalidLogLevel, l.Level))
+	}
+	v.oneOf("logging.output", l.Output, "stdout", "file", "both", "none")
+	v.between("logging.queue_size", l.QueueSize, 0, 100000)
+	v.oneOf("logging.overflow", l.Overflow, "block", "drop")
+	for _, pattern := range l.Redact.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.addf("logging.redact.patterns", "%q 不是有效的正则表达式: %v", pattern, err)
+		}
+	}
+	if l.Output == "file" || l.Output == "both" {
+		if strings.TrimSpace(l.FilePath) == "" {
+			v.addf("logging.file_path", "输出到文件时不能为空")
+		}
+		v.between("logging.max_size_mb", l.MaxSizeMB, 1, 1024)
+		v.between("logging.max_backups", l.MaxBackups, 0, 100)
+		v.between("logging.max_age_days", l.MaxAgeDays, 0, 365)
+		v.between("logging.max_total_mb", l.MaxTotalMB, 0, 102400)
+	}
+}
+
+func (t *TrayConfig) validate(v *validator) {
+	if t.Icon == "" {
+		return
+	}
+	if _, err := os.Stat(t.Icon); err != nil {
+		v.addf("tray.icon", "图标文件 %q 不存在", t.Icon)
+	}
+}
+
+func (a *AudioConfig) validate(v *validator) {
+	v.between("audio.volume", a.Volume, 0, 100)
+	v.oneOf("audio.sink", a.Sink, "device", "file", "none")
+	if a.Sink == "file" && a.OutputDir == "" {
+		v.addf("audio.output_dir", "sink 为 file 时不能为空")
+	}
+	for i, m := range a.Mute {
+		for _, clock := range []struct{ name, value string }{{"start", m.Start}, {"end", m.End}} {
+			if _, err := time.Parse("15:04", clock.value); err != nil {
+				v.addf(fmt.Sprintf("audio.mute[%d].%s", i, clock.name), "%q 不是 HH:MM 格式", clock.value)
+			}
+		}
+	}
+}
+
+func (q *QueueConfig) validate(v *validator) {
+	if q.ElderlyAge < 0 {
+		v.addf("queue.elderly_age", "不能为负数")
+	}
+	if q.RevisitInterval < 0 {
+		v.addf("queue.revisit_interval", "不能为负数")
+	}
+}
+
+func (d *DisplayConfig) validate(v *validator) {
+	if !d.Enabled {
+		return
+	}
+	if d.Port == "" {
+		v.addf("display.port", "不能为空")
+	}
+	v.oneOf("display.baud_rate", fmt.Sprint(d.BaudRate),
+		"1200", "2400", "4800", "9600", "19200", "38400", "57600", "115200", "230400")
+	v.oneOf("display.encoding", strings.ToLower(d.Encoding), "gb2312", "gbk", "utf8", "utf-8")
+	v.oneOf("display.checksum", d.Checksum, "none", "sum8", "xor", "crc16")
+	v.hex("display.header", d.Header)
+	v.hex("display.footer", d.Footer)
+	v.between("display.address", d.Address, -1, 255)
+	v.between("display.length_bytes", d.LengthBytes, 0, 2)
+}
+
+func (p *PrinterConfig) validate(v *validator) {
+	if !p.Enabled {
+		return
+	}
+	v.oneOf("printer.output", p.Output, "tcp", "file")
+	if p.Output == "tcp" && p.Address == "" {
+		v.addf("printer.address", "output 为 tcp 时不能为空")
+	}
+	if p.Output == "file" && p.FilePath == "" {
+		v.addf("printer.file_path", "output 为 file 时不能为空")
+	}
+	if p.TimeoutMs < 0 {
+		v.addf("printer.timeout_ms", "不能为负数")
+	}
+}
+
+func (r *RemoteConfig) validate(v *validator) {
+	if !r.Enabled {
+		return
+	}
+	key, err := base64.StdEncoding.DecodeString(r.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		v.addf("remote.public_key", "必须为 Base64 编码的 %d 字节 Ed25519 公钥", ed25519.PublicKeySize)
+	}
+	v.between("remote.interval_sec", r.IntervalSec, 30, 86400)
+}
+
+func (d *DiagnosticsConfig) validate(v *validator) {
+	if d.UploadURL != "" {
+		u, err := url.Parse(d.UploadURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addf("diagnostics.upload_url", "%q 不是合法的 http(s) 地址", d.UploadURL)
+		}
+	}
+	v.between("diagnostics.log_max_mb", d.LogMaxMB, 1, 100)
+}
+
+// countPortVerbs 统计启动参数模板中 %d 的数量，%% 为字面量，出现其他占位符时返回 false
+func countPortVerbs(format string) (int, bool) {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 >= len(format) {
+			return n, false
+		}
+		i++
+		switch format[i] {
+		case '%':
+		case 'd':
+			n++
+		default:
+			return n, false
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() *Config {
+	cfg := Default()
+	cfg.Tray.Icon = ""
+	return cfg
+}
+
+func TestValidateDefault(t *testing.T) {
+	assert.NoError(t, validConfig().Validate())
+}
+
+func TestDefaultAudioCommand(t *testing.T) {
+	assert.Equal(t, "aplay", defaultAudioCommand("linux")[0])
+	assert.Equal(t, "powershell", defaultAudioCommand("windows")[0])
+	assert.Contains(t, defaultAudioCommand("darwin")[2], "afplay")
+}
+
+func TestValidateAggregatesErrors(t *testing.T) {
+	cfg := validConfig()
+	cfg.App.MinWidth = 800
+	cfg.App.MaxWidth = 600
+	cfg.Logging.Level = "verbose"
+	cfg.Tray.Icon = filepath.Join(t.TempDir(), "missing.ico")
+	cfg.Audio.Volume = 120
+	cfg.Audio.Mute = []MuteWindow{{Start: "12:00", End: "25:00"}}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidLogLevel)
+
+	message := err.Error()
+	for _, path := range []string{"app.min_width", "logging.level", "tray.icon", "audio.volume", "audio.mute[0].end"} {
+		assert.Contains(t, message, path+":")
+	}
+	assert.Len(t, strings.Split(message, "\n"), 5)
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+}
+
+func TestValidateWindowSize(t *testing.T) {
+	cfg := validConfig()
+	cfg.App.Width = cfg.App.MinWidth - 1
+	cfg.App.Height = cfg.App.MaxHeight + 1
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "app.width:")
+	assert.ErrorContains(t, err, "app.height:")
+
+	// max 为 0 时不限制
+	cfg = validConfig()
+	cfg.App.MaxHeight = 0
+	cfg.App.Height = 2000
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidateOptionalSections(t *testing.T) {
+	cfg := validConfig()
+	cfg.Display.Checksum = "md5"
+	cfg.Printer.Output = "usb"
+	assert.NoError(t, cfg.Validate(), "未启用的外设不校验")
+
+	cfg.Display.Enabled = true
+	cfg.Printer.Enabled = true
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "display.checksum")
+	assert.ErrorContains(t, err, "printer.output")
+}
+
+func TestValidateProcess(t *testing.T) {
+	cfg := validConfig()
+	cfg.Process.ExePath = " "
+	cfg.Process.Port = 70000
+	cfg.Process.Args = "--port=%s"
+	cfg.Process.StartRetry = 0
+
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, ErrEmptyExePath)
+	assert.ErrorIs(t, err, ErrInvalidPort)
+	assert.ErrorContains(t, err, "process.args")
+	assert.ErrorContains(t, err, "process.start_retry")
+
+	for _, args := range []string{"", "--port=%d", "--serve", "--port=%d --rate=100%%"} {
+		cfg = validConfig()
+		cfg.Process.Args = args
+		assert.NoError(t, cfg.Validate(), args)
+	}
+	for _, args := range []string{"--port=%d --host=%s", "--port=%d --admin=%d", "--rate=100%"} {
+		cfg = validConfig()
+		cfg.Process.Args = args
+		assert.ErrorContains(t, cfg.Validate(), "process.args", args)
+	}
+}
+
+func TestValidateLogging(t *testing.T) {
+	cfg := validConfig()
+	for _, output := range []string{"stdout", "file", "both", "none"} {
+		cfg.Logging.Output = output
+		assert.NoError(t, cfg.Validate(), output)
+	}
+
+	cfg.Logging.Output = "syslog"
+	assert.ErrorContains(t, cfg.Validate(), "logging.output")
+
+	cfg.Logging.Output = "file"
+	cfg.Logging.FilePath = ""
+	cfg.Logging.MaxSizeMB = 0
+	cfg.Logging.MaxTotalMB = -1
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "logging.file_path")
+	assert.ErrorContains(t, err, "logging.max_size_mb")
+	assert.ErrorContains(t, err, "logging.max_total_mb")
+
+	// 不输出到文件时不校验轮转参数
+	cfg.Logging.Output = "stdout"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Logging.Overflow = "wait"
+	cfg.Logging.QueueSize = -1
+	err = cfg.Validate()
+	assert.ErrorContains(t, err, "logging.overflow")
+	assert.ErrorContains(t, err, "logging.queue_size")
+
+	cfg.Logging.Redact.Patterns = []string{`MRN\d+`, "("}
+	err = cfg.Validate()
+	assert.ErrorContains(t, err, "logging.redact.patterns")
+	assert.NotContains(t, err.Error(), "MRN")
+}
+
+func TestValidateDiagnostics(t *testing.T) {
+	cfg := validConfig()
+	cfg.Diagnostics.UploadURL = "https://support.example.com/api/diagnostics"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Diagnostics.UploadURL = "ftp://support.example.com"
+	cfg.Diagnostics.LogMaxMB = 0
+	err := cfg.Validate()
+	assert.ErrorContains(t, err, "diagnostics.upload_url")
+	assert.ErrorContains(t, err, "diagnostics.log_max_mb")
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Load(filepath.Join(dir, "missing.toml"))
+	assert.ErrorIs(t, err, ErrConfigNotFound)
+
+	broken := filepath.Join(dir, "broken.toml")
+	assert.NoError(t, os.WriteFile(broken, []byte("[app\nwidth = "), 0644))
+	_, err = Load(broken)
+	assert.ErrorIs(t, err, ErrConfigParseFailed)
+
+	valid := filepath.Join(dir, "app.toml")
+	assert.NoError(t, os.WriteFile(valid, []byte("[app]\nwidth = 500\n[tray]\nicon = \"\"\n"), 0644))
+	cfg, err := Load(valid)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, cfg.App.Width)
+	assert.Equal(t, 700, cfg.App.Height)
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveFields 无需重启即可生效的配置项
+var liveFields = map[string]bool{
+	"logging.level":     true,
+	"tray.title":        true,
+	"tray.tooltip":      true,
+	"tray.icon":         true,
+	"app.title":         true,
+	"app.always_on_top": true,
+	"app.width":         true,
+	"app.height":        true,
+	"app.min_width":     true,
+	"app.min_height":    true,
+	"app.max_width":     true,
+	"app.max_height":    true,
+
+	"diagnostics.upload_url":   true,
+	"diagnostics.upload_token": true,
+	"diagnostics.log_max_mb":   true,
+}
+
+// Change 配置项变化
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// RequiresRestart 该配置项变化后是否需要重启才能生效
+func (c Change) RequiresRestart() bool {
+	return !liveFields[c.Path]
+}
+
+// MergeLive 返回 current 的副本，无需重启的配置项取 latest 的值，其余保持 current 中实际生效的值
+func MergeLive(current, latest *Config) *Config {
+	next := *current
+	values := make(map[string]reflect.Value)
+	for _, f := range collectFields(latest) {
+		values[f.path] = f.value
+	}
+	for _, f := range collectFields(&next) {
+		if liveFields[f.path] {
+			f.value.Set(values[f.path])
+		}
+	}
+	return &next
+}
+
+// Diff 比较两份配置，返回所有变化的配置项
+// 结构体切片（如 audio.mute）作为整体比较
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	var walk func(prefix string, a, b reflect.Value)
+	walk = func(prefix string, a, b reflect.Value) {
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			tag := strings.Split(t.Field(i).Tag.Get("toml"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			path := tag
+			if prefix != "" {
+				path = prefix + "." + tag
+			}
+
+			fa, fb := a.Field(i), b.Field(i)
+			if fa.Kind() == reflect.Struct {
+				walk(path, fa, fb)
+				continue
+			}
+			if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+				changes = append(changes, Change{Path: path, Old: fa.Interface(), New: fb.Interface()})
+			}
+		}
+	}
+	walk("", reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem())
+	return changes
+}
+
+// ReloadFunc 配置重新加载后的回调
+type ReloadFunc func(old, new *Config, changes []Change)
+
+// Watcher 轮询配置文件变化并重新加载
+// 使用轮询而不是文件系统通知，保证在网络盘和各平台上都能工作
+type Watcher struct {
+	opts     Options
+	interval time.Duration
+	current  *Config
+	onReload ReloadFunc
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// NewWatcher 创建配置文件监视器，opts 与启动时加载配置使用的参数相同
+func NewWatcher(opts Options, current *Config, interval time.Duration, onReload ReloadFunc) *Watcher {
+	w := &Watcher{
+		opts:     opts,
+		interval: interval,
+		current:  current,
+		onReload: onReload,
+	}
+	w.snapshot()
+	return w
+}
+
+// Run 开始轮询，直到 ctx 结束
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.check(); err != nil {
+				slog.Error("重新加载配置失败，继续使用当前配置", "path", w.opts.Path, "error", err)
+			}
+		}
+	}
+}
+
+// check 检查文件是否变化，变化时重新加载并校验
+func (w *Watcher) check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.opts.Path)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(w.opts.Path)
+	if err != nil {
+		return err
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	hash := sha256.Sum256(data)
+	if bytes.Equal(hash[:], w.hash[:]) {
+		return nil
+	}
+	w.hash = hash
+
+	_, err = w.reload("配置文件已变化")
+	return err
+}
+
+// Options 返回当前使用的加载参数，包含最新的远程配置层
+func (w *Watcher) Options() Options {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.opts
+}
+
+// SetRemote 替换远程配置层并立即重新加载，校验失败时保留原远程配置
+func (w *Watcher) SetRemote(data []byte) (*Result, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prev := w.opts.Remote
+	w.opts.Remote = data
+	result, err := w.reload("远程配置已变化")
+	if err != nil {
+		w.opts.Remote = prev
+		return nil, err
+	}
+	return result, nil
+}
+
+// reload 重新加载配置并通知变化，调用方需持有 mu
+func (w *Watcher) reload(reason string) (*Result, error) {
+	result, err := LoadLayered(w.opts)
+	if err != nil {
+		return nil, fmt.Errorf("配置校验失败: %w", err)
+	}
+	result.WarnUnknown()
+
+	old := w.current
+	w.current = result.Config
+	changes := Diff(old, result.Config)
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	slog.Info(reason, "path", w.opts.Path, "changes", len(changes))
+	if w.onReload != nil {
+		w.onReload(old, result.Config, changes)
+	}
+	return result, nil
+}
+
+// snapshot 记录当前文件状态
+func (w *Watcher) snapshot() {
+	info, err := os.Stat(w.opts.Path)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(w.opts.Path)
+	if err != nil {
+		return
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	w.hash = sha256.Sum256(data)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := Default()
+	new := Default()
+	new.Logging.Level = "info"
+	new.App.Width = 500
+	new.Audio.Mute = []MuteWindow{{Start: "12:00", End: "13:00"}}
+
+	changes := Diff(old, new)
+	assert.Len(t, changes, 3)
+
+	byPath := make(map[string]Change)
+	for _, c := range changes {
+		byPath[c.Path] = c
+	}
+	assert.Equal(t, "debug", byPath["logging.level"].Old)
+	assert.Equal(t, "info", byPath["logging.level"].New)
+	assert.False(t, byPath["logging.level"].RequiresRestart())
+	assert.False(t, byPath["app.width"].RequiresRestart())
+	assert.True(t, byPath["audio.mute"].RequiresRestart())
+}
+
+func TestMergeLive(t *testing.T) {
+	current := Default()
+	latest := Default()
+	latest.App.Width = 500
+	latest.Logging.Level = "info"
+	latest.Logging.Output = "stdout"
+	latest.Process.Port = 9000
+
+	// 只合并无需重启的配置项，剩下的差异即待重启生效的配置
+	merged := MergeLive(current, latest)
+	assert.Equal(t, 500, merged.App.Width)
+	assert.Equal(t, "info", merged.Logging.Level)
+	assert.Equal(t, current.Logging.Output, merged.Logging.Output)
+	assert.Equal(t, current.Process.Port, merged.Process.Port)
+	assert.Equal(t, Default().App.Width, current.App.Width, "不修改原配置")
+
+	var pending []string
+	for _, c := range Diff(merged, latest) {
+		pending = append(pending, c.Path)
+	}
+	assert.ElementsMatch(t, []string{"logging.output", "process.port"}, pending)
+}
+
+func TestWatcherReload(t *testing.T) {
+	path := writeConfig(t, "[tray]\nicon = \"\"\ntooltip = \"一诊室\"\n")
+	opts := Options{Path: path}
+
+	result, err := LoadLayered(opts)
+	assert.NoError(t, err)
+
+	var reloaded []Change
+	w := NewWatcher(opts, result.Config, time.Millisecond, func(old, new *Config, changes []Change) {
+		reloaded = changes
+	})
+
+	// 未变化
+	assert.NoError(t, w.check())
+	assert.Nil(t, reloaded)
+
+	// 非法配置不替换当前配置
+	assert.NoError(t, os.WriteFile(path, []byte("[tray]\nicon = \"\"\n[logging]\nlevel = \"loud\"\n"), 0644))
+	assert.Error(t, w.check())
+	assert.Equal(t, "一诊室", w.current.Tray.Tooltip)
+
+	assert.NoError(t, os.WriteFile(path, []byte("[tray]\nicon = \"\"\ntooltip = \"二诊室\"\n"), 0644))
+	assert.NoError(t, w.check())
+	assert.Equal(t, []Change{{Path: "tray.tooltip", Old: "一诊室", New: "二诊室"}}, reloaded)
+}
+
+func TestWatcherSetRemote(t *testing.T) {
+	path := writeConfig(t, "[tray]\nicon = \"\"\ntooltip = \"一诊室\"\n")
+	result, err := LoadLayered(Options{Path: path})
+	assert.NoError(t, err)
+
+	var reloaded []Change
+	w := NewWatcher(Options{Path: path}, result.Config, time.Millisecond, func(old, new *Config, changes []Change) {
+		reloaded = changes
+	})
+
+	remote, err := w.SetRemote([]byte("[tray]\ntooltip = \"远程\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tray.tooltip"}, remote.Overridden(SourceRemote))
+	assert.Equal(t, []Change{{Path: "tray.tooltip", Old: "一诊室", New: "远程"}}, reloaded)
+
+	// 非法的远程配置不替换当前远程层
+	_, err = w.SetRemote([]byte("[app]\nwidth = -1\n"))
+	assert.Error(t, err)
+	assert.Equal(t, "远程", w.current.Tray.Tooltip)
+	assert.Equal(t, "[tray]\ntooltip = \"远程\"\n", string(w.opts.Remote))
+}
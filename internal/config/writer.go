@@ -0,0 +1,475 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EditablePaths 允许在设置页修改的配置项
+var EditablePaths = []string{
+	"app.title",
+	"app.width",
+	"app.height",
+	"app.min_width",
+	"app.min_height",
+	"app.max_width",
+	"app.max_height",
+	"app.disable_resize",
+	"app.always_on_top",
+	"tray.title",
+	"tray.tooltip",
+	"tray.icon",
+}
+
+// Editable 返回可编辑配置项的当前值，应在解析相对路径前的配置（Result.Raw）上调用
+func (c *Config) Editable() map[string]any {
+	values := make(map[string]any, len(EditablePaths))
+	for _, f := range collectFields(c) {
+		if isEditable(f.path) {
+			values[f.path] = f.value.Interface()
+		}
+	}
+	return values
+}
+
+// ApplyEdits 将编辑内容应用到解析相对路径前的配置副本，按 opts 解析路径后校验，返回未解析路径的新配置
+// values 来自前端 JSON，数字为 float64
+func (c *Config) ApplyEdits(values map[string]any, opts Options) (*Config, error) {
+	next := *c
+	fields := make(map[string]reflect.Value)
+	for _, f := range collectFields(&next) {
+		fields[f.path] = f.value
+	}
+
+	schema := JSONSchema()
+	var v validator
+	for path, value := range values {
+		if !isEditable(path) {
+			v.addf(path, "不允许在设置页修改")
+			continue
+		}
+		// 先按 JSON Schema 校验类型和范围，再做跨字段校验
+		if err := schema.Lookup(path).Check(value); err != nil {
+			v.add(path, err)
+			continue
+		}
+		if err := assign(fields[path], value); err != nil {
+			v.add(path, err)
+		}
+	}
+	if len(v.errs) > 0 {
+		return nil, errors.Join(v.errs...)
+	}
+
+	// 与 LoadLayered 一致，相对路径基于程序或数据目录校验
+	resolved := next
+	opts.resolvePaths(&resolved)
+	if err := resolved.Validate(); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// SaveEdits 校验编辑内容并写回 current.Path，只写入实际变化的键，返回解析相对路径前的新配置
+// current 为 LoadLayered 的结果，被环境变量、命令行或远程配置覆盖的键写入文件后不会生效，直接拒绝
+func SaveEdits(current *Result, opts Options, values map[string]any) (*Config, error) {
+	var v validator
+	for key := range values {
+		switch current.Sources[key] {
+		case SourceEnv:
+			v.addf(key, "已由环境变量 %s 覆盖，修改配置文件不会生效", EnvName(key))
+		case SourceFlag:
+			v.addf(key, "已由命令行参数 --%s 覆盖，修改配置文件不会生效", key)
+		case SourceRemote:
+			v.addf(key, "由集中配置下发，不能在本机修改")
+		}
+	}
+	if len(v.errs) > 0 {
+		return nil, errors.Join(v.errs...)
+	}
+
+	next, err := current.Raw.ApplyEdits(values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]any)
+	for _, c := range Diff(current.Raw, next) {
+		changed[c.Path] = c.New
+	}
+	if len(changed) == 0 {
+		return next, nil
+	}
+	if err := WriteFile(current.Path, changed); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// WriteFile 只更新配置文件中变化的键，保留注释和顺序
+// 先写临时文件再替换，原文件备份为 .bak
+func WriteFile(path string, values map[string]any) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	updated, err := updateDocument(original, values)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, original) {
+		return nil
+	}
+
+	if err := os.WriteFile(path+".bak", original, 0644); err != nil {
+		return fmt.Errorf("备份配置文件失败: %w", err)
+	}
+	return writeAtomic(path, updated)
+}
+
+// updateDocument 在 TOML 文本中替换或插入键值
+// 按行处理，跨行的数组和多行字符串无法安全替换，要修改这类键时返回错误
+func updateDocument(doc []byte, values map[string]any) ([]byte, error) {
+	newline := "\n"
+	if bytes.Contains(doc, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	lines := strings.Split(strings.ReplaceAll(string(doc), "\r\n", "\n"), "\n")
+
+	pending := make(map[string]string, len(values))
+	for path, value := range values {
+		encoded, err := encodeValue(value)
+		if err != nil {
+			return nil, &FieldError{Path: path, Err: err}
+		}
+		pending[path] = encoded
+	}
+
+	// 替换已存在的键，同时记录每个表最后一个键所在行
+	section := ""
+	lastKeyLine := map[string]int{}
+	headerLine := map[string]int{}
+	closer, depth := "", 0 // 未结束的多行值：多行字符串的结束引号，或数组的 ]
+	for i, line := range lines {
+		if closer != "" {
+			// 多行值的后续行，不解析其中的 [ 和 =
+			lastKeyLine[section] = i
+			if closer == "]" {
+				depth += bracketDepth(line)
+				if depth <= 0 {
+					closer = ""
+				}
+			} else if strings.Contains(line, closer) {
+				closer = ""
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			section = strings.Trim(strings.SplitN(trimmed, "#", 2)[0], "[] \t")
+			headerLine[section] = i
+			continue
+		}
+
+		key, valueStart, ok := parseKeyLine(line)
+		if !ok {
+			continue
+		}
+		lastKeyLine[section] = i
+
+		path := key
+		if section != "" {
+			path = section + "." + key
+		}
+		encoded, ok := pending[path]
+		if closer, depth = unterminated(line[valueStart:]); closer != "" {
+			if ok {
+				return nil, &FieldError{Path: path, Err: errors.New("配置文件中为多行值，不能在设置页修改，请直接编辑配置文件")}
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		lines[i] = line[:valueStart] + encoded + trailingComment(line[valueStart:])
+		delete(pending, path)
+	}
+
+	// 插入文件中不存在的键
+	for _, path := range sortedKeys(pending) {
+		section, key := splitPath(path)
+		entry := key + " = " + pending[path]
+
+		if at, ok := lastKeyLine[section]; ok {
+			lines = insertLine(lines, at+1, entry)
+			shiftAfter(lastKeyLine, headerLine, at, section)
+			continue
+		}
+		if at, ok := headerLine[section]; ok {
+			lines = insertLine(lines, at+1, entry)
+			shiftAfter(lastKeyLine, headerLine, at, section)
+			lastKeyLine[section] = at + 1
+			continue
+		}
+
+		// 表不存在，追加到文件末尾
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = append(lines, "", "["+section+"]", entry, "")
+		headerLine[section] = len(lines) - 3
+		lastKeyLine[section] = len(lines) - 2
+	}
+
+	return []byte(strings.Join(lines, newline)), nil
+}
+
+// parseKeyLine 解析 key = value 行，返回键名和值的起始位置
+func parseKeyLine(line string) (string, int, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", 0, false
+	}
+
+	eq := strings.Index(line, "=")
+	if eq < 0 {
+		return "", 0, false
+	}
+	key := strings.Trim(strings.TrimSpace(line[:eq]), `"`)
+
+	start := eq + 1
+	for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+		start++
+	}
+	return key, start, true
+}
+
+// unterminated 判断值是否延续到下一行，返回结束标记：多行字符串为对应的三引号，数组为 ] 及未闭合的层数
+func unterminated(value string) (string, int) {
+	for _, delim := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(value, delim) {
+			if strings.Contains(value[len(delim):], delim) {
+				return "", 0
+			}
+			return delim, 0
+		}
+	}
+	if strings.HasPrefix(value, "[") {
+		if depth := bracketDepth(value); depth > 0 {
+			return "]", depth
+		}
+	}
+	return "", 0
+}
+
+// bracketDepth 返回一行中 [ 与 ] 的数量差，跳过字符串和注释
+func bracketDepth(line string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// trailingComment 返回值之后的空白和注释，跳过字符串中的 #
+func trailingComment(rest string) string {
+	var quote byte
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			end := i
+			for end > 0 && (rest[end-1] == ' ' || rest[end-1] == '\t') {
+				end--
+			}
+			return rest[end:]
+		}
+	}
+	return ""
+}
+
+// encodeValue 按 TOML 格式编码值，字符串使用双引号与原文件风格一致
+func encodeValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	return "", fmt.Errorf("不支持写入的类型 %T", value)
+}
+
+// quote 编码 TOML 基本字符串
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7F {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// assign 将前端传入的值赋给配置字段
+func assign(field reflect.Value, value any) error {
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("必须为字符串")
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("必须为布尔值")
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, ok := value.(float64)
+		if i, isInt := value.(int); isInt {
+			n, ok = float64(i), true
+		}
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("必须为整数")
+		}
+		field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("不支持的类型 %s", field.Kind())
+	}
+	return nil
+}
+
+// writeAtomic 写入临时文件后替换目标文件
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func isEditable(path string) bool {
+	return slices.Contains(EditablePaths, path)
+}
+
+func splitPath(path string) (string, string) {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+func insertLine(lines []string, at int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[at+1:], lines[at:])
+	lines[at] = line
+	return lines
+}
+
+// shiftAfter 插入一行后，修正插入点之后的行号
+func shiftAfter(lastKeyLine, headerLine map[string]int, at int, section string) {
+	for s, line := range lastKeyLine {
+		if line > at {
+			lastKeyLine[s] = line + 1
+		}
+	}
+	for s, line := range headerLine {
+		if line > at {
+			headerLine[s] = line + 1
+		}
+	}
+	if line, ok := lastKeyLine[section]; ok && line == at {
+		lastKeyLine[section] = at + 1
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const writerSample = `# 应用配置
+[app]
+# 窗口标题
+title = "呼叫客户端" # 标题栏显示
+width = 480
+height = 640
+
+[tray]
+icon = ""
+`
+
+func TestUpdateDocument(t *testing.T) {
+	out, err := updateDocument([]byte(writerSample), map[string]any{
+		"app.title":     `一诊室 "#1"`,
+		"app.width":     1280,
+		"app.min_width": 800,
+		"tray.tooltip":  "呼叫",
+		"logging.level": "info",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `# 应用配置
+[app]
+# 窗口标题
+title = "一诊室 \"#1\"" # 标题栏显示
+width = 1280
+height = 640
+min_width = 800
+
+[tray]
+icon = ""
+tooltip = "呼叫"
+
+[logging]
+level = "info"
+`, string(out))
+}
+
+func TestUpdateDocumentMultiline(t *testing.T) {
+	doc := `[app]
+title = """
+width = 1
+"""
+width = 1024
+
+[audio]
+command = [
+  "aplay", # 播放命令
+  "-q", "-",
+]
+volume = 80
+`
+	// 多行值中的内容不当作键
+	out, err := updateDocument([]byte(doc), map[string]any{"app.width": 1280, "audio.sink": "none"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "title = \"\"\"\nwidth = 1\n\"\"\"\nwidth = 1280\n")
+	assert.Contains(t, string(out), "volume = 80\nsink = \"none\"\n")
+
+	// 不修改多行值
+	_, err = updateDocument([]byte(doc), map[string]any{"app.title": "一诊室"})
+	assert.ErrorContains(t, err, "多行值")
+	_, err = updateDocument([]byte(doc), map[string]any{"audio.command": []string{"paplay"}})
+	assert.ErrorContains(t, err, "多行值")
+}
+
+func TestTrailingComment(t *testing.T) {
+	assert.Equal(t, " # 注释", trailingComment(`"a # b" # 注释`))
+	assert.Equal(t, "", trailingComment(`'a#b'`))
+	assert.Equal(t, "", trailingComment(`12`))
+}
+
+func TestSaveEdits(t *testing.T) {
+	path := writeConfig(t, writerSample)
+	load := func() *Result {
+		result, err := LoadLayered(Options{Path: path})
+		assert.NoError(t, err)
+		return result
+	}
+
+	// JSON 数字为 float64
+	next, err := SaveEdits(load(), Options{}, map[string]any{"app.width": float64(600), "tray.tooltip": "二诊室"})
+	assert.NoError(t, err)
+	assert.Equal(t, 600, next.App.Width)
+
+	reloaded := load()
+	assert.Equal(t, 600, reloaded.Config.App.Width)
+	assert.Equal(t, "二诊室", reloaded.Config.Tray.Tooltip)
+
+	backup, err := os.ReadFile(path + ".bak")
+	assert.NoError(t, err)
+	assert.Equal(t, writerSample, string(backup))
+
+	// 非法值和不可编辑项不写入
+	_, err = SaveEdits(reloaded, Options{}, map[string]any{"app.width": float64(-1), "logging.level": "info"})
+	var fe *FieldError
+	assert.ErrorAs(t, err, &fe)
+	_, err = SaveEdits(reloaded, Options{}, map[string]any{"app.width": 1.5})
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "width = 600")
+	assert.Contains(t, string(data), "# 标题栏显示")
+
+	// 被其他来源覆盖的配置项不写入文件
+	reloaded.Sources["app.width"] = SourceEnv
+	reloaded.Sources["tray.tooltip"] = SourceRemote
+	_, err = SaveEdits(reloaded, Options{}, map[string]any{"app.width": float64(900), "tray.tooltip": "三诊室"})
+	assert.ErrorContains(t, err, "SWCALL_APP_WIDTH")
+	assert.ErrorContains(t, err, "集中配置")
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "width = 600")
+}
+
+func TestSaveEditsRelativePath(t *testing.T) {
+	path := writeConfig(t, writerSample)
+	exeDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(exeDir, "icon.ico"), []byte("ico"), 0644))
+	opts := Options{Path: path, ExeDir: exeDir}
+
+	// 相对路径基于程序目录校验，文件中保留相对路径
+	current, err := LoadLayered(opts)
+	assert.NoError(t, err)
+	next, err := SaveEdits(current, opts, map[string]any{"tray.icon": "icon.ico"})
+	assert.NoError(t, err)
+	assert.Equal(t, "icon.ico", next.Editable()["tray.icon"])
+
+	reloaded, err := LoadLayered(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(exeDir, "icon.ico"), reloaded.Config.Tray.Icon)
+	assert.Equal(t, "icon.ico", reloaded.Raw.Editable()["tray.icon"])
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `icon = "icon.ico"`)
+
+	_, err = SaveEdits(reloaded, opts, map[string]any{"tray.icon": "missing.ico"})
+	assert.ErrorContains(t, err, "tray.icon")
+}
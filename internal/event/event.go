@@ -0,0 +1,70 @@
+package event
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Type 患者事件类型
+type Type string
+
+const (
+	// TypeCall 呼叫患者
+	TypeCall Type = "call"
+	// TypePass 患者过号
+	TypePass Type = "pass"
+	// TypeEnd 患者结诊
+	TypeEnd Type = "end"
+	// TypeMove 患者转到其他诊室，Room 为新诊室
+	TypeMove Type = "move"
+)
+
+// Event 患者事件
+type Event struct {
+	Type    Type   `json:"type"`
+	Number  string `json:"number"`   // 排队号
+	Name    string `json:"name"`     // 患者姓名
+	Room    string `json:"room"`     // 诊室名称
+	VisitID string `json:"visit_id"` // 就诊ID
+}
+
+// Handler 事件处理函数
+type Handler func(Event) error
+
+// Bus 事件总线，按事件类型分发给订阅者
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]namedHandler
+}
+
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Type][]namedHandler)}
+}
+
+// Subscribe 订阅一种或多种事件
+func (b *Bus) Subscribe(name string, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, t := range types {
+		b.handlers[t] = append(b.handlers[t], namedHandler{name: name, handler: handler})
+	}
+}
+
+// Publish 同步分发事件，单个订阅者失败不影响其他订阅者
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.Type]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h.handler(e); err != nil {
+			slog.Error("处理患者事件失败", "subscriber", h.name, "type", e.Type, "error", err)
+		}
+	}
+}
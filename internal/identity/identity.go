@@ -0,0 +1,190 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"sw_call/pkg/storage"
+	"sw_call/pkg/system"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ClientIDKey 客户端ID在存储中的键
+	ClientIDKey = "client_id"
+	// FingerprintKey 硬件指纹在存储中的键
+	FingerprintKey = "client_fingerprint"
+)
+
+// Fingerprint 硬件指纹
+type Fingerprint struct {
+	MachineID  string `json:"machine_id"`  // 操作系统机器ID
+	MAC        string `json:"mac"`         // 主网卡 MAC 地址
+	DiskSerial string `json:"disk_serial"` // 系统盘序列号，Windows 上为系统卷序列号
+}
+
+// Identity 客户端身份
+type Identity struct {
+	ClientID    string      `json:"client_id"`
+	Fingerprint Fingerprint `json:"fingerprint"`
+	Changed     []string    `json:"changed,omitempty"` // 与上次记录相比发生变化的硬件项
+}
+
+// Collector 硬件指纹采集函数
+type Collector func() Fingerprint
+
+// Collect 通过 pkg/system 采集当前机器的硬件指纹
+func Collect() Fingerprint {
+	var fp Fingerprint
+	var err error
+
+	if fp.MachineID, err = system.GetMachineID(); err != nil {
+		slog.Warn("获取机器ID失败", "error", err)
+	}
+	if fp.MAC, err = system.GetPrimaryMAC(); err != nil {
+		slog.Warn("获取主网卡MAC失败", "error", err)
+	}
+	if fp.DiskSerial, err = system.GetDiskSerial(); err != nil {
+		slog.Warn("获取磁盘序列号失败", "error", err)
+	}
+
+	return fp
+}
+
+// IsEmpty 是否没有采集到任何硬件信息
+func (f Fingerprint) IsEmpty() bool {
+	return f.MachineID == "" && f.MAC == "" && f.DiskSerial == ""
+}
+
+// Hash 返回指纹的哈希值
+func (f Fingerprint) Hash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.MachineID, f.MAC, f.DiskSerial}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff 返回与另一个指纹相比发生变化的硬件项
+func (f Fingerprint) Diff(other Fingerprint) []string {
+	var changed []string
+	if f.MachineID != other.MachineID {
+		changed = append(changed, "machine_id")
+	}
+	if f.MAC != other.MAC {
+		changed = append(changed, "mac")
+	}
+	if f.DiskSerial != other.DiskSerial {
+		changed = append(changed, "disk_serial")
+	}
+	return changed
+}
+
+// DeriveID 由硬件指纹派生客户端ID
+// 同一台机器重装后派生结果不变，服务端仍能识别为同一设备
+func DeriveID(f Fingerprint) string {
+	if f.IsEmpty() {
+		// 无法获取任何硬件信息时退化为随机ID
+		return strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return f.Hash()[:32]
+}
+
+// LoadClientID 加载客户端身份，不存在时由硬件指纹生成并保存
+func LoadClientID(store *storage.DataStore) (*Identity, error) {
+	return load(store, Collect)
+}
+
+func load(store *storage.DataStore, collect Collector) (*Identity, error) {
+	current := collect()
+
+	clientID := loadStoredID(store)
+	if clientID == "" {
+		identity := &Identity{
+			ClientID:    DeriveID(current),
+			Fingerprint: current,
+		}
+		if err := save(store, identity); err != nil {
+			return nil, err
+		}
+		slog.Info("生成客户端ID", "client_id", identity.ClientID)
+		return identity, nil
+	}
+
+	identity := &Identity{
+		ClientID:    clientID,
+		Fingerprint: current,
+	}
+
+	previous, ok := loadFingerprint(store)
+	if ok {
+		identity.Changed = previous.Diff(current)
+		if len(identity.Changed) == 0 {
+			return identity, nil
+		}
+		slog.Warn("检测到硬件变化", "client_id", clientID, "changed", identity.Changed)
+	}
+
+	// 旧版本只保存了客户端ID，或者硬件发生了变化，更新指纹但保留原有ID
+	if err := saveFingerprint(store, current); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// loadStoredID 读取已保存的客户端ID
+func loadStoredID(store *storage.DataStore) string {
+	entry, err := store.Load(ClientIDKey)
+	if err != nil || entry.Data == nil {
+		return ""
+	}
+	id, _ := entry.Data.(string)
+	return id
+}
+
+// loadFingerprint 读取已保存的硬件指纹
+func loadFingerprint(store *storage.DataStore) (Fingerprint, bool) {
+	var fp Fingerprint
+
+	entry, err := store.Load(FingerprintKey)
+	if err != nil || entry.Data == nil {
+		return fp, false
+	}
+
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return fp, false
+	}
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return fp, false
+	}
+	return fp, true
+}
+
+// save 保存客户端ID与硬件指纹
+func save(store *storage.DataStore, identity *Identity) error {
+	entry := &storage.DataEntry{
+		ID:   ClientIDKey,
+		Type: "config",
+		Data: identity.ClientID,
+	}
+	if err := store.Save(entry); err != nil {
+		return fmt.Errorf("保存客户端ID失败: %w", err)
+	}
+	return saveFingerprint(store, identity.Fingerprint)
+}
+
+// saveFingerprint 保存硬件指纹
+func saveFingerprint(store *storage.DataStore, fp Fingerprint) error {
+	entry := &storage.DataEntry{
+		ID:   FingerprintKey,
+		Type: "config",
+		Data: fp,
+	}
+	if err := store.Save(entry); err != nil {
+		return fmt.Errorf("保存硬件指纹失败: %w", err)
+	}
+	return nil
+}
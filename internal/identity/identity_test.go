@@ -0,0 +1,75 @@
+package identity
+
+import (
+	"testing"
+
+	"sw_call/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) *storage.DataStore {
+	store, err := storage.InitDataStore(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func fixedCollector(fp Fingerprint) Collector {
+	return func() Fingerprint { return fp }
+}
+
+func TestLoadClientID(t *testing.T) {
+	fp := Fingerprint{MachineID: "abc", MAC: "00:11:22:33:44:55", DiskSerial: "SN1"}
+
+	t.Run("派生ID稳定", func(t *testing.T) {
+		first, err := load(newTestStore(t), fixedCollector(fp))
+		assert.NoError(t, err)
+
+		// 模拟重装：新的空存储，相同硬件
+		second, err := load(newTestStore(t), fixedCollector(fp))
+		assert.NoError(t, err)
+
+		assert.Equal(t, first.ClientID, second.ClientID)
+		assert.Len(t, first.ClientID, 32)
+	})
+
+	t.Run("硬件变化保留ID", func(t *testing.T) {
+		store := newTestStore(t)
+		first, err := load(store, fixedCollector(fp))
+		assert.NoError(t, err)
+
+		changed := fp
+		changed.MAC = "66:77:88:99:aa:bb"
+		second, err := load(store, fixedCollector(changed))
+		assert.NoError(t, err)
+		assert.Equal(t, first.ClientID, second.ClientID)
+		assert.Equal(t, []string{"mac"}, second.Changed)
+
+		// 指纹已更新，再次加载不再报告变化
+		third, err := load(store, fixedCollector(changed))
+		assert.NoError(t, err)
+		assert.Empty(t, third.Changed)
+	})
+
+	t.Run("兼容旧版客户端ID", func(t *testing.T) {
+		store := newTestStore(t)
+		assert.NoError(t, store.Save(&storage.DataEntry{ID: ClientIDKey, Type: "config", Data: "legacy"}))
+
+		id, err := load(store, fixedCollector(fp))
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", id.ClientID)
+
+		saved, ok := loadFingerprint(store)
+		assert.True(t, ok)
+		assert.Equal(t, fp, saved)
+	})
+
+	t.Run("无硬件信息时随机生成", func(t *testing.T) {
+		a, err := load(newTestStore(t), fixedCollector(Fingerprint{}))
+		assert.NoError(t, err)
+		b, err := load(newTestStore(t), fixedCollector(Fingerprint{}))
+		assert.NoError(t, err)
+		assert.NotEqual(t, a.ClientID, b.ClientID)
+	})
+}
@@ -4,41 +4,28 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"sw_call/internal/config"
 	"sw_call/pkg/storage"
 )
 
-func InitApp(path string) error {
-	InitLogger(path)
-	err := InitStore(path)
-	if err != nil {
-		return err
+// InitApp 初始化日志和数据存储，两者互不影响
+// 日志初始化失败时继续使用默认日志输出；数据存储是必需的，无法打开时返回错误
+func InitApp(path string, logging *config.LoggingConfig) error {
+	if err := InitLogger(logging); err != nil {
+		slog.Error("初始化日志失败，使用默认日志输出", "error", err)
 	}
-
-	return nil
+	return InitStore(path)
 }
 
 func InitStore(path string) error {
 	// storage 目录
 	dataPath := filepath.Join(path, "storage")
 
-	// 初始化数据存储
-	ds, err := storage.InitDataStore(dataPath)
-	if err != nil {
+	// 初始化数据存储，客户端ID由 App 加载一次后缓存
+	if _, err := storage.InitDataStore(dataPath); err != nil {
 		return fmt.Errorf("初始化数据存储失败: %v", err)
 	}
-
-	var entry *storage.DataEntry
-	entry, err = ds.Load("client_id")
-	if err != nil {
-		entry = CreateClientID()
-		ds.Save(entry)
-	}
-
-	if entry.Data == nil {
-		entry = CreateClientID()
-		ds.Save(entry)
-	}
-	slog.Info("客户端ID", slog.Any("client_id", entry.Data))
+	slog.Info("数据存储", "path", dataPath)
 
 	return nil
 }
@@ -0,0 +1,29 @@
+package initialize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitApp(t *testing.T) {
+	dir := t.TempDir()
+
+	// 日志配置错误不影响数据存储
+	logging := config.Default().Logging
+	logging.Level = "verbose"
+	assert.NoError(t, InitApp(dir, &logging))
+	store := storage.GetInstance()
+	t.Cleanup(func() { store.Close() })
+	assert.NoError(t, store.Save(&storage.DataEntry{ID: "k", Data: "v"}))
+
+	// 数据存储无法打开时返回错误
+	blocked := filepath.Join(t.TempDir(), "root")
+	assert.NoError(t, os.WriteFile(blocked, nil, 0o644))
+	assert.ErrorContains(t, InitApp(blocked, &logging), "初始化数据存储失败")
+}
@@ -1,19 +1,44 @@
 package initialize
 
 import (
-	"path/filepath"
+	"log/slog"
+
+	"sw_call/internal/config"
 	"sw_call/pkg/logger"
 )
 
-func InitLogger(path string) {
+func InitLogger(logging *config.LoggingConfig) error {
+	level, err := logger.ParseLevel(logging.Level)
+	if err != nil {
+		return err
+	}
+
 	cfg := new(logger.Config)
-	cfg.Level = -1
-	cfg.Path = filepath.Join(path, "logs")
-	cfg.MaxSize = 50 // MB
-	cfg.MaxBackups = 5
-	cfg.MaxAge = 10 // days
-	cfg.Compress = true
-	cfg.Mode = "debug"
+	cfg.Level = level
+	cfg.Output = logging.Output
+	cfg.Path = logging.FilePath
+	cfg.MaxSize = logging.MaxSizeMB // MB
+	cfg.MaxBackups = logging.MaxBackups
+	cfg.MaxAge = logging.MaxAgeDays   // days
+	cfg.MaxTotal = logging.MaxTotalMB // MB
+	cfg.Daily = logging.Daily
+	cfg.Compress = logging.Compress
+	cfg.QueueSize = logging.QueueSize
+	cfg.Overflow = logging.Overflow
+	if logging.Redact.Enabled {
+		cfg.Redactor, err = logger.NewRedactor(logger.RedactConfig{
+			Keys:     logging.Redact.Keys,
+			NameKeys: logging.Redact.NameKeys,
+			IDCard:   logging.Redact.IDCard,
+			Phone:    logging.Redact.Phone,
+			Patterns: logging.Redact.Patterns,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	logger.NewLoggerWrapper(*cfg)
+	slog.Info("日志初始化完成", "level", cfg.Level, "output", cfg.Output, "path", cfg.Path)
+	return nil
 }
@@ -0,0 +1,77 @@
+package paths
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// migrateDirs 切换目录时需要迁移的内容，日志不迁移
+var migrateDirs = []string{"storage", "configs"}
+
+// migrate 将旧 root 目录中的数据复制到新 root 目录，旧数据保留以便回退
+// 先复制到临时目录再改名，避免中途失败留下不完整的数据
+func migrate(from, to string) error {
+	for _, name := range migrateDirs {
+		src := filepath.Join(from, name)
+		dst := filepath.Join(to, name)
+		if !exists(src) || exists(dst) {
+			continue
+		}
+
+		tmp := dst + ".migrating"
+		if err := os.RemoveAll(tmp); err != nil {
+			return err
+		}
+		if err := copyDir(src, tmp); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+		if err := os.Rename(tmp, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDir 递归复制目录
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		// leveldb 的 LOCK 文件由打开数据库的进程持有，无需复制
+		if d.Name() == "LOCK" {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,267 @@
+package paths
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// AppName 每用户目录下的应用目录名
+	AppName = "sw_call"
+	// RootDir 配置、数据、日志所在的子目录，与发布包中的 root 目录一致
+	RootDir = "root"
+	// PortableMarker 可执行文件旁存在该文件时使用便携模式
+	PortableMarker = "portable"
+	// DataDirEnv 指定数据目录的环境变量
+	DataDirEnv = "SWCALL_DATA_DIR"
+
+	dataDirFlag = "--data-dir"
+	stateFile   = "location.json"
+)
+
+// Mode 目录模式
+type Mode string
+
+const (
+	// ModePortable 便携模式，数据保存在可执行文件旁
+	ModePortable Mode = "portable"
+	// ModeUser 每用户模式，数据保存在系统的用户数据目录（XDG_DATA_HOME、AppData）
+	ModeUser Mode = "user"
+	// ModeCustom 通过 --data-dir 或 SWCALL_DATA_DIR 指定
+	ModeCustom Mode = "custom"
+)
+
+// Paths 解析后的目录
+type Paths struct {
+	Mode         Mode   `json:"mode"`
+	Base         string `json:"base"`                    // 数据目录，日志、存储等相对路径以此为基准
+	ExeDir       string `json:"-"`                       // 可执行文件所在目录，图标、呼叫进程等随程序发布的资源以此为基准
+	Config       string `json:"-"`                       // 配置文件路径
+	MigratedFrom string `json:"migrated_from,omitempty"` // 本次启动迁移数据的来源目录
+}
+
+// Root 返回 root 目录
+func (p *Paths) Root() string {
+	return filepath.Join(p.Base, RootDir)
+}
+
+// ConfigFile 返回配置文件路径，总是位于数据目录中
+// 首次启动时由随程序发布的配置文件复制而来，没有发布的配置文件时写入默认配置
+func (p *Paths) ConfigFile() string {
+	return p.Config
+}
+
+// StorageDir 返回数据存储目录
+func (p *Paths) StorageDir() string {
+	return filepath.Join(p.Root(), "storage")
+}
+
+// Options 目录解析参数，字段为空时使用系统默认值
+type Options struct {
+	DataDir   string // 显式指定的数据目录
+	ExeDir    string // 可执行文件所在目录
+	UserDir   string // 每用户数据目录
+	WorkDir   string // 当前工作目录，旧版本在此创建 root 目录
+	StateFile string // 记录上次使用目录的文件
+	Template  []byte // 数据目录和程序目录都没有配置文件时写入的默认配置
+}
+
+// ParseArgs 从命令行参数和环境变量中取出数据目录，返回去掉 --data-dir 后的参数
+func ParseArgs(args, environ []string) (string, []string) {
+	var dataDir string
+	for _, kv := range environ {
+		if value, ok := strings.CutPrefix(kv, DataDirEnv+"="); ok {
+			dataDir = value
+		}
+	}
+
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if value, ok := strings.CutPrefix(arg, dataDirFlag+"="); ok {
+			dataDir = value
+			continue
+		}
+		if arg == dataDirFlag && i+1 < len(args) {
+			dataDir = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return dataDir, rest
+}
+
+// Detect 按优先级确定目录：显式指定 > 便携模式 > 每用户模式
+// 目录与上次启动不同且新目录没有数据时，从上次目录或当前工作目录迁移
+func Detect(opts Options) (*Paths, error) {
+	if err := fillDefaults(&opts); err != nil {
+		return nil, err
+	}
+
+	p := &Paths{ExeDir: opts.ExeDir}
+	switch {
+	case opts.DataDir != "":
+		base, err := filepath.Abs(opts.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		p.Mode, p.Base = ModeCustom, base
+	case isPortable(opts.ExeDir):
+		p.Mode, p.Base = ModePortable, opts.ExeDir
+	default:
+		p.Mode, p.Base = ModeUser, opts.UserDir
+	}
+
+	if err := os.MkdirAll(p.Root(), 0755); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	// 新目录没有数据时，依次尝试上次使用的目录和旧版本的工作目录
+	if !exists(p.StorageDir()) {
+		candidates := []string{}
+		if last, err := loadState(opts.StateFile); err == nil {
+			candidates = append(candidates, last.Base)
+		}
+		candidates = append(candidates, opts.WorkDir)
+		for _, from := range candidates {
+			if from == "" || samePath(from, p.Base) || !exists(filepath.Join(from, RootDir, "storage")) {
+				continue
+			}
+			if err := migrate(filepath.Join(from, RootDir), p.Root()); err != nil {
+				return nil, fmt.Errorf("从 %s 迁移数据失败: %w", from, err)
+			}
+			p.MigratedFrom = from
+			break
+		}
+	}
+
+	// 配置文件放在数据目录中，设置页保存时不需要写程序目录（通常只读）
+	p.Config = configFile(p.Root())
+	if !exists(p.Config) {
+		content := opts.Template
+		if bundled := configFile(filepath.Join(opts.ExeDir, RootDir)); exists(bundled) {
+			data, err := os.ReadFile(bundled)
+			if err != nil {
+				return nil, fmt.Errorf("读取发布的配置文件失败: %w", err)
+			}
+			content = data
+		}
+		if len(content) > 0 {
+			if err := os.MkdirAll(filepath.Dir(p.Config), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(p.Config, content, 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := saveState(opts.StateFile, p); err != nil {
+		return nil, fmt.Errorf("保存目录记录失败: %w", err)
+	}
+	return p, nil
+}
+
+// fillDefaults 补全系统默认目录
+func fillDefaults(opts *Options) error {
+	if opts.ExeDir == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return err
+		}
+		opts.ExeDir = filepath.Dir(exe)
+	}
+	if opts.WorkDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		opts.WorkDir = wd
+	}
+
+	if opts.UserDir == "" {
+		dir, err := userDataDir()
+		if err != nil {
+			return err
+		}
+		opts.UserDir = filepath.Join(dir, AppName)
+	}
+	if opts.StateFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		opts.StateFile = filepath.Join(dir, AppName, stateFile)
+	}
+	return nil
+}
+
+// userDataDir 返回系统的用户数据目录
+// Windows、macOS 使用 os.UserConfigDir（AppData、Application Support），其他系统遵循 XDG
+func userDataDir() (string, error) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return os.UserConfigDir()
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// isPortable 可执行文件旁有便携标记或已有数据时使用便携模式
+func isPortable(exeDir string) bool {
+	return exists(filepath.Join(exeDir, PortableMarker)) ||
+		exists(filepath.Join(exeDir, RootDir, "storage"))
+}
+
+func configFile(root string) string {
+	return filepath.Join(root, "configs", "app.toml")
+}
+
+func loadState(path string) (*Paths, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Paths
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func saveState(path string, p *Paths) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(Paths{Mode: p.Mode, Base: p.Base}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+func samePath(a, b string) bool {
+	a, _ = filepath.Abs(a)
+	b, _ = filepath.Abs(b)
+	return filepath.Clean(a) == filepath.Clean(b)
+}
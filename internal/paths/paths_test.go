@@ -0,0 +1,117 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func testOptions(t *testing.T) Options {
+	dir := t.TempDir()
+	return Options{
+		ExeDir:    filepath.Join(dir, "bin"),
+		UserDir:   filepath.Join(dir, "user"),
+		WorkDir:   filepath.Join(dir, "cwd"),
+		StateFile: filepath.Join(dir, "state", "location.json"),
+		Template:  []byte("# 默认配置\n"),
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	dir, rest := ParseArgs([]string{"--app.width=500", "--data-dir", "/data", "--print-config"}, nil)
+	assert.Equal(t, "/data", dir)
+	assert.Equal(t, []string{"--app.width=500", "--print-config"}, rest)
+
+	dir, rest = ParseArgs([]string{"--data-dir=/flag"}, []string{"SWCALL_DATA_DIR=/env"})
+	assert.Equal(t, "/flag", dir, "命令行优先于环境变量")
+	assert.Empty(t, rest)
+
+	dir, _ = ParseArgs(nil, []string{"SWCALL_DATA_DIR=/env"})
+	assert.Equal(t, "/env", dir)
+}
+
+func TestDetectModes(t *testing.T) {
+	opts := testOptions(t)
+	writeFile(t, filepath.Join(opts.ExeDir, "root", "configs", "app.toml"), "# 发布的配置\n")
+
+	// 默认使用每用户目录，首次启动复制随程序发布的配置文件
+	p, err := Detect(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeUser, p.Mode)
+	assert.Equal(t, opts.UserDir, p.Base)
+	assert.Equal(t, filepath.Join(opts.UserDir, "root", "configs", "app.toml"), p.ConfigFile())
+	assert.Equal(t, filepath.Join(opts.UserDir, "root", "storage"), p.StorageDir())
+	data, err := os.ReadFile(p.ConfigFile())
+	assert.NoError(t, err)
+	assert.Equal(t, "# 发布的配置\n", string(data))
+
+	// 之后不再覆盖用户修改过的配置
+	writeFile(t, p.ConfigFile(), "# 用户修改\n")
+	p, err = Detect(opts)
+	assert.NoError(t, err)
+	data, err = os.ReadFile(p.ConfigFile())
+	assert.NoError(t, err)
+	assert.Equal(t, "# 用户修改\n", string(data))
+
+	// 便携标记
+	writeFile(t, filepath.Join(opts.ExeDir, PortableMarker), "")
+	p, err = Detect(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ModePortable, p.Mode)
+	assert.Equal(t, opts.ExeDir, p.Base)
+
+	// 显式指定，没有任何配置时写入默认配置
+	opts.DataDir = filepath.Join(t.TempDir(), "data")
+	os.RemoveAll(filepath.Join(opts.ExeDir, "root", "configs"))
+	p, err = Detect(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeCustom, p.Mode)
+	data, err = os.ReadFile(p.ConfigFile())
+	assert.NoError(t, err)
+	assert.Equal(t, "# 默认配置\n", string(data))
+}
+
+func TestDetectMigratesLegacyWorkDir(t *testing.T) {
+	opts := testOptions(t)
+	writeFile(t, filepath.Join(opts.WorkDir, "root", "storage", "000001.log"), "leveldb")
+	writeFile(t, filepath.Join(opts.WorkDir, "root", "storage", "LOCK"), "")
+	writeFile(t, filepath.Join(opts.WorkDir, "root", "configs", "app.toml"), "# 旧配置\n")
+
+	p, err := Detect(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, opts.WorkDir, p.MigratedFrom)
+
+	data, err := os.ReadFile(filepath.Join(p.StorageDir(), "000001.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "leveldb", string(data))
+	assert.NoFileExists(t, filepath.Join(p.StorageDir(), "LOCK"))
+	assert.Equal(t, filepath.Join(opts.UserDir, "root", "configs", "app.toml"), p.ConfigFile())
+
+	// 旧数据保留，再次启动不重复迁移
+	assert.FileExists(t, filepath.Join(opts.WorkDir, "root", "storage", "000001.log"))
+	p, err = Detect(opts)
+	assert.NoError(t, err)
+	assert.Empty(t, p.MigratedFrom)
+}
+
+func TestDetectMigratesOnModeChange(t *testing.T) {
+	opts := testOptions(t)
+	p, err := Detect(opts)
+	assert.NoError(t, err)
+	writeFile(t, filepath.Join(p.StorageDir(), "CURRENT"), "MANIFEST-000001")
+
+	// 切换到 --data-dir 时从上次的每用户目录迁移
+	opts.DataDir = filepath.Join(t.TempDir(), "data")
+	p, err = Detect(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeCustom, p.Mode)
+	assert.Equal(t, opts.UserDir, p.MigratedFrom)
+	assert.FileExists(t, filepath.Join(p.StorageDir(), "CURRENT"))
+}
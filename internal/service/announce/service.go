@@ -0,0 +1,173 @@
+package announce
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"sw_call/pkg/tts"
+)
+
+var (
+	// ErrQueueFull 播报队列已满
+	ErrQueueFull = errors.New("announcement queue is full")
+	// ErrNothingToRecall 没有可重呼的播报
+	ErrNothingToRecall = errors.New("no announcement to recall")
+)
+
+const (
+	defaultQueueSize = 32
+	// repeatGap 同一条播报重复之间的间隔
+	repeatGap = 800 * time.Millisecond
+	maxRepeat = 5
+)
+
+// Announcement 一条待播报内容
+type Announcement struct {
+	Text   string `json:"text"`
+	Number string `json:"number"` // 排队号，日志中代替含患者姓名的文本
+	Room   string `json:"room"`   // 诊室名称
+	Repeat int    `json:"repeat"` // 重复播报次数
+	Volume int    `json:"volume"` // 音量 0-100
+	Urgent bool   `json:"urgent"` // 是否为紧急呼叫
+}
+
+// Prelude 每条播报开始前执行，例如播放提示音
+type Prelude func(ctx context.Context, a Announcement) error
+
+// Service 呼叫播报服务，按顺序逐条播报
+type Service struct {
+	synth tts.Synthesizer
+	queue chan Announcement
+	gap   time.Duration
+
+	mu      sync.Mutex
+	last    *Announcement
+	prelude Prelude
+	muted   func() bool
+}
+
+// NewService 创建呼叫播报服务
+func NewService(synth tts.Synthesizer) *Service {
+	return &Service{
+		synth: synth,
+		queue: make(chan Announcement, defaultQueueSize),
+		gap:   repeatGap,
+	}
+}
+
+// Available 是否有可用的语音合成器，不可用时只执行前置动作（提示音）
+func (s *Service) Available() bool {
+	return tts.Available(s.synth)
+}
+
+// SetPrelude 设置播报前执行的动作
+func (s *Service) SetPrelude(fn Prelude) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prelude = fn
+}
+
+// SetMuted 设置静音判断，静音时段内跳过整条播报
+func (s *Service) SetMuted(fn func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.muted = fn
+}
+
+// Announce 将播报加入队列
+func (s *Service) Announce(a Announcement) error {
+	if a.Repeat < 1 {
+		a.Repeat = 1
+	}
+	if a.Repeat > maxRepeat {
+		a.Repeat = maxRepeat
+	}
+
+	select {
+	case s.queue <- a:
+	default:
+		return ErrQueueFull
+	}
+
+	s.mu.Lock()
+	s.last = &a
+	s.mu.Unlock()
+	return nil
+}
+
+// AnnounceCall 使用模板生成呼叫文本并加入队列
+func (s *Service) AnnounceCall(template string, call Call, repeat, volume int, urgent bool) error {
+	return s.Announce(Announcement{
+		Text:   Render(template, call),
+		Number: call.Number,
+		Room:   call.Room,
+		Repeat: repeat,
+		Volume: volume,
+		Urgent: urgent,
+	})
+}
+
+// Recall 重新播报上一条内容
+func (s *Service) Recall() error {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+
+	if last == nil {
+		return ErrNothingToRecall
+	}
+	return s.Announce(*last)
+}
+
+// Run 逐条处理播报队列，直到 ctx 结束
+func (s *Service) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case a := <-s.queue:
+			s.play(ctx, a)
+		}
+	}
+}
+
+// play 播报一条内容，按重复次数播放
+func (s *Service) play(ctx context.Context, a Announcement) {
+	s.mu.Lock()
+	prelude, muted := s.prelude, s.muted
+	s.mu.Unlock()
+
+	if muted != nil && muted() {
+		slog.Info("静音时段，跳过语音播报", "number", a.Number, "room", a.Room)
+		return
+	}
+
+	if prelude != nil {
+		if err := prelude(ctx, a); err != nil {
+			slog.Warn("播报前置动作失败", "number", a.Number, "room", a.Room, "error", err)
+		}
+	}
+
+	for i := 0; i < a.Repeat; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.gap):
+			}
+		}
+
+		speech := tts.Speech{Text: a.Text, Volume: a.Volume}
+		if err := s.synth.Speak(ctx, speech); errors.Is(err, tts.ErrUnavailable) {
+			slog.Warn("语音播报不可用，只播放提示音", "number", a.Number, "room", a.Room)
+			return
+		} else if err != nil {
+			slog.Error("语音播报失败", "number", a.Number, "room", a.Room, "error", err)
+			return
+		}
+	}
+	slog.Info("语音播报完成", "number", a.Number, "room", a.Room, "repeat", a.Repeat)
+}
@@ -0,0 +1,162 @@
+package announce
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sw_call/pkg/tts"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSynth 记录播报内容的合成器
+type recordingSynth struct {
+	mu     sync.Mutex
+	spoken []tts.Speech
+}
+
+func (r *recordingSynth) Speak(ctx context.Context, speech tts.Speech) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spoken = append(r.spoken, speech)
+	return nil
+}
+
+func (r *recordingSynth) Spoken() []tts.Speech {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]tts.Speech(nil), r.spoken...)
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		call     Call
+		want     string
+	}{
+		{"默认模板", "", Call{Number: "12", Name: "张三", Room: "3 诊室"}, "请 12 号 张三 到 3 诊室就诊"},
+		{"自定义模板", "{number}号{name}请到{room}", Call{Number: "5", Name: "李四", Room: "2诊室"}, "5号李四请到2诊室"},
+		{"缺少占位符", "请候诊", Call{Number: "1"}, "请候诊"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.template, tt.call))
+		})
+	}
+}
+
+func TestServiceQueue(t *testing.T) {
+	synth := &recordingSynth{}
+	svc := NewService(synth)
+	svc.gap = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var preludes []string
+	svc.SetPrelude(func(ctx context.Context, a Announcement) error {
+		preludes = append(preludes, a.Text)
+		return nil
+	})
+
+	assert.ErrorIs(t, svc.Recall(), ErrNothingToRecall)
+
+	assert.NoError(t, svc.AnnounceCall("", Call{Number: "1", Name: "张三", Room: "1诊室"}, 2, 60, false))
+	assert.NoError(t, svc.Announce(Announcement{Text: "请安静候诊"}))
+	assert.NoError(t, svc.Recall())
+
+	go svc.Run(ctx)
+
+	assert.Eventually(t, func() bool { return len(synth.Spoken()) == 4 }, time.Second, 5*time.Millisecond)
+
+	spoken := synth.Spoken()
+	assert.Equal(t, "请 1 号 张三 到 1诊室就诊", spoken[0].Text)
+	assert.Equal(t, 60, spoken[0].Volume)
+	assert.Equal(t, spoken[0], spoken[1])
+	assert.Equal(t, "请安静候诊", spoken[2].Text)
+	assert.Equal(t, "请安静候诊", spoken[3].Text)
+
+	// 重复播报只在开始前执行一次前置动作
+	assert.Len(t, preludes, 3)
+}
+
+func TestServiceQueueFull(t *testing.T) {
+	svc := NewService(&recordingSynth{})
+
+	for i := 0; i < defaultQueueSize; i++ {
+		assert.NoError(t, svc.Announce(Announcement{Text: "x"}))
+	}
+	assert.ErrorIs(t, svc.Announce(Announcement{Text: "x"}), ErrQueueFull)
+}
+
+func TestServiceUnavailable(t *testing.T) {
+	svc := NewService(tts.NewNullSynthesizer())
+	svc.gap = time.Millisecond
+	assert.False(t, svc.Available())
+	assert.True(t, NewService(&recordingSynth{}).Available())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 没有语音合成器时仍然播放提示音
+	preludes := make(chan string, 1)
+	svc.SetPrelude(func(ctx context.Context, a Announcement) error {
+		preludes <- a.Text
+		return nil
+	})
+	assert.NoError(t, svc.AnnounceCall("", Call{Number: "1", Name: "张三", Room: "1诊室"}, 2, 60, false))
+	go svc.Run(ctx)
+
+	select {
+	case text := <-preludes:
+		assert.Equal(t, "请 1 号 张三 到 1诊室就诊", text)
+	case <-time.After(time.Second):
+		t.Fatal("没有播放提示音")
+	}
+}
+
+func TestServiceMuted(t *testing.T) {
+	synth := &recordingSynth{}
+	svc := NewService(synth)
+	var muted atomic.Bool
+	muted.Store(true)
+	svc.SetMuted(muted.Load)
+
+	var preludes atomic.Int32
+	svc.SetPrelude(func(ctx context.Context, a Announcement) error {
+		preludes.Add(1)
+		return nil
+	})
+
+	// 静音时段内既不播放提示音也不播报
+	svc.play(context.Background(), Announcement{Text: "请 1 号到 1诊室就诊", Repeat: 1})
+	assert.Empty(t, synth.Spoken())
+	assert.Zero(t, preludes.Load())
+
+	muted.Store(false)
+	svc.play(context.Background(), Announcement{Text: "请 1 号到 1诊室就诊", Repeat: 1})
+	assert.Len(t, synth.Spoken(), 1)
+	assert.Equal(t, int32(1), preludes.Load())
+}
+
+func TestServiceLogOmitsName(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(prev)
+
+	svc := NewService(tts.NullSynthesizer{})
+	assert.NoError(t, svc.AnnounceCall("", Call{Number: "12", Name: "张三", Room: "3 诊室"}, 1, 80, false))
+	svc.play(context.Background(), <-svc.queue)
+
+	// 播报文本含患者姓名，日志只记录排队号和诊室
+	assert.Contains(t, buf.String(), "number=12")
+	assert.NotContains(t, buf.String(), "张三")
+}
@@ -0,0 +1,27 @@
+package announce
+
+import "strings"
+
+// DefaultTemplate 默认呼叫播报模板
+const DefaultTemplate = "请 {number} 号 {name} 到 {room}就诊"
+
+// Call 呼叫信息
+type Call struct {
+	Number string `json:"number"` // 排队号
+	Name   string `json:"name"`   // 患者姓名
+	Room   string `json:"room"`   // 诊室名称
+}
+
+// Render 使用模板生成播报文本
+func Render(template string, call Call) string {
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	replacer := strings.NewReplacer(
+		"{number}", call.Number,
+		"{name}", call.Name,
+		"{room}", call.Room,
+	)
+	return replacer.Replace(template)
+}
@@ -0,0 +1,44 @@
+package caller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sw_call/internal/config"
+	"sw_call/internal/errors"
+)
+
+func TestStartStop(t *testing.T) {
+	startupWait = 50 * time.Millisecond
+
+	// sleep 21999 模拟常驻进程，参数中的 %d 替换为端口
+	svc := NewService(&config.ProcessConfig{ExePath: "sleep", Port: 21999, Args: "%d", StartRetry: 1})
+	assert.Error(t, svc.HealthCheck(context.Background()))
+
+	assert.NoError(t, svc.Start(context.Background()))
+	assert.True(t, svc.IsRunning())
+	assert.NoError(t, svc.HealthCheck(context.Background()))
+	assert.Equal(t, 21999, svc.GetPort())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, svc.Stop(ctx))
+	assert.False(t, svc.IsRunning())
+}
+
+func TestStartFailed(t *testing.T) {
+	startupWait = 200 * time.Millisecond
+
+	for _, exe := range []string{"/nonexistent/caller", "true"} {
+		svc := NewService(&config.ProcessConfig{ExePath: exe, Port: 1, Args: "%d", StartRetry: 2, RetryDelayMs: 1})
+		err := svc.Start(context.Background())
+
+		var callerErr *errors.CallerError
+		assert.ErrorAs(t, err, &callerErr, exe)
+		assert.Equal(t, errors.ErrCodeProcessStartFailed, callerErr.Code)
+		assert.False(t, svc.IsRunning())
+	}
+}
@@ -0,0 +1,14 @@
+package caller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartArgs(t *testing.T) {
+	assert.Nil(t, startArgs("", 21999))
+	assert.Equal(t, []string{"--port=21999"}, startArgs("--port=%d", 21999))
+	assert.Equal(t, []string{"--serve"}, startArgs("--serve", 21999))
+	assert.Equal(t, []string{"--rate=100%"}, startArgs("--rate=100%%", 21999))
+}
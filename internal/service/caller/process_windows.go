@@ -0,0 +1,14 @@
+package caller
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow 不显示控制台窗口
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000,
+	}
+}
@@ -1,24 +1,26 @@
 package caller
 
+import "context"
+
 // ProcessService 进程服务接口
-// type ProcessService interface {
-// 	// Start 启动进程
-// 	Start(ctx context.Context) error
-// 	// Stop 停止进程
-// 	Stop(ctx context.Context) error
-// 	// IsRunning 检查进程是否运行中
-// 	IsRunning() bool
-// 	// HealthCheck 健康检查
-// 	HealthCheck(ctx context.Context) error
-// 	// GetPort 获取进程端口
-// 	GetPort() int
-// }
+type ProcessService interface {
+	// Start 启动进程
+	Start(ctx context.Context) error
+	// Stop 停止进程
+	Stop(ctx context.Context) error
+	// IsRunning 检查进程是否运行中
+	IsRunning() bool
+	// HealthCheck 健康检查
+	HealthCheck(ctx context.Context) error
+	// GetPort 获取进程端口
+	GetPort() int
+}
 
-// // Config 服务配置
-// type Config struct {
-// 	ExePath    string
-// 	Port       int
-// 	Args       string
-// 	StartRetry int
-// 	RetryDelay int
-// }
+// Config 服务配置
+type Config struct {
+	ExePath    string
+	Port       int
+	Args       string
+	StartRetry int
+	RetryDelay int // 毫秒
+}
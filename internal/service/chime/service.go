@@ -0,0 +1,149 @@
+package chime
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/audio"
+)
+
+//go:embed assets/*.wav
+var assets embed.FS
+
+const (
+	// SoundChime 普通呼叫前的提示音
+	SoundChime = "chime"
+	// SoundUrgent 紧急呼叫提示音
+	SoundUrgent = "urgent"
+)
+
+// Service 提示音播放服务
+type Service struct {
+	player *audio.Player
+	clips  map[string]*audio.Clip
+}
+
+// NewService 根据配置创建提示音服务
+func NewService(cfg *config.AudioConfig) (*Service, error) {
+	sink, err := newSink(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newService(sink, cfg)
+}
+
+func newService(sink audio.Sink, cfg *config.AudioConfig) (*Service, error) {
+	clips, err := loadClips()
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule audio.Schedule
+	for _, m := range cfg.Mute {
+		window, err := audio.ParseMuteWindow(m.Start, m.End)
+		if err != nil {
+			return nil, fmt.Errorf("静音时段配置错误: %w", err)
+		}
+		schedule = append(schedule, window)
+	}
+
+	player := audio.NewPlayer(sink, 0)
+	player.SetVolume(cfg.Volume)
+	player.SetSchedule(schedule)
+
+	return &Service{
+		player: player,
+		clips:  clips,
+	}, nil
+}
+
+// Sounds 返回可用的提示音名称
+func (s *Service) Sounds() []string {
+	names := make([]string, 0, len(s.clips))
+	for name := range s.clips {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetVolume 设置音量 0-100
+func (s *Service) SetVolume(volume int) {
+	s.player.SetVolume(volume)
+}
+
+// Play 将提示音加入播放队列，紧急提示音优先播放
+func (s *Service) Play(name string, priority audio.Priority) error {
+	clip, ok := s.clips[name]
+	if !ok {
+		return fmt.Errorf("未知的提示音: %s", name)
+	}
+	return s.player.Enqueue(name, clip, priority)
+}
+
+// PlayWait 将提示音加入播放队列并等待播放结束，需要先启动 Run
+func (s *Service) PlayWait(ctx context.Context, name string, priority audio.Priority) error {
+	clip, ok := s.clips[name]
+	if !ok {
+		return fmt.Errorf("未知的提示音: %s", name)
+	}
+	return s.player.EnqueueWait(ctx, name, clip, priority)
+}
+
+// Muted 当前是否处于静音时段
+func (s *Service) Muted() bool {
+	return s.player.Muted()
+}
+
+// Run 处理播放队列，直到 ctx 结束
+func (s *Service) Run(ctx context.Context) {
+	s.player.Run(ctx)
+}
+
+// loadClips 解码内嵌的 WAV 资源
+func loadClips() (map[string]*audio.Clip, error) {
+	entries, err := assets.ReadDir("assets")
+	if err != nil {
+		return nil, err
+	}
+
+	clips := make(map[string]*audio.Clip, len(entries))
+	for _, entry := range entries {
+		data, err := assets.ReadFile(path.Join("assets", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		clip, err := audio.DecodeWAV(data)
+		if err != nil {
+			return nil, fmt.Errorf("解码提示音 %s 失败: %w", entry.Name(), err)
+		}
+		clips[strings.TrimSuffix(entry.Name(), ".wav")] = clip
+	}
+	return clips, nil
+}
+
+// newSink 根据配置创建音频输出
+func newSink(cfg *config.AudioConfig) (audio.Sink, error) {
+	switch cfg.Sink {
+	case "file":
+		return audio.NewFileSink(cfg.OutputDir)
+	case "none":
+		return audio.NullSink{}, nil
+	default:
+		if len(cfg.Command) == 0 {
+			return audio.NullSink{}, nil
+		}
+		sink := audio.NewCommandSink(cfg.Command[0], cfg.Command[1:]...)
+		if !sink.Available() {
+			slog.Warn("未找到播放命令，提示音不可用", "command", cfg.Command[0])
+			return audio.NullSink{}, nil
+		}
+		return sink, nil
+	}
+}
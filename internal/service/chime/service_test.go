@@ -0,0 +1,75 @@
+package chime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/audio"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceRendersToFile(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.AudioConfig{Volume: 50, Sink: "file", OutputDir: dir}
+
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{SoundChime, SoundUrgent}, svc.Sounds())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	assert.NoError(t, svc.PlayWait(ctx, SoundChime, audio.PriorityNormal))
+	assert.Error(t, svc.PlayWait(ctx, "missing", audio.PriorityNormal))
+
+	data, err := os.ReadFile(filepath.Join(dir, "000001.wav"))
+	assert.NoError(t, err)
+
+	rendered, err := audio.DecodeWAV(data)
+	assert.NoError(t, err)
+	original := svc.clips[SoundChime]
+	assert.Equal(t, len(original.Samples), len(rendered.Samples))
+	assert.Equal(t, original.Samples[1000]/2, rendered.Samples[1000])
+}
+
+func TestServicePriority(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := audio.NewFileSink(dir)
+	assert.NoError(t, err)
+
+	svc, err := newService(sink, &config.AudioConfig{Volume: 100})
+	assert.NoError(t, err)
+
+	// 先入队，再启动播放，紧急提示音应先播放
+	assert.NoError(t, svc.Play(SoundChime, audio.PriorityNormal))
+	assert.NoError(t, svc.Play(SoundUrgent, audio.PriorityUrgent))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.Run(ctx)
+
+	second := filepath.Join(dir, "000002.wav")
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(second)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	data, err := os.ReadFile(filepath.Join(dir, "000001.wav"))
+	assert.NoError(t, err)
+	first, err := audio.DecodeWAV(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(svc.clips[SoundUrgent].Samples), len(first.Samples))
+}
+
+func TestServiceInvalidMute(t *testing.T) {
+	_, err := newService(audio.NullSink{}, &config.AudioConfig{
+		Mute: []config.MuteWindow{{Start: "25:00", End: "13:00"}},
+	})
+	assert.Error(t, err)
+}
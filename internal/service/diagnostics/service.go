@@ -0,0 +1,429 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/logger"
+	"sw_call/pkg/storage"
+	"sw_call/pkg/system"
+)
+
+const (
+	// uploadTimeout 上传诊断包的超时时间
+	uploadTimeout = 2 * time.Minute
+	// fileTimeFormat 诊断包文件名中的时间格式
+	fileTimeFormat = "20060102-150405"
+	// logFlushTimeout 打包前等待异步日志写完的时间
+	logFlushTimeout = 2 * time.Second
+)
+
+// logFiles MultiLevelHandler 写入的当前文件，另外打包每个日志最近的 logBackups 个轮转文件
+var logFiles = []string{"debug.log", "info.log", "error.log"}
+
+// logBackups 每个日志打包的轮转文件数量，问题常发生在上次轮转之前
+const logBackups = 2
+
+// Options 诊断包的数据来源
+type Options struct {
+	Version    string
+	ClientID   string
+	Config     *config.Config
+	Store      *storage.DataStore
+	StorageDir string
+	LogDir     string
+}
+
+// Service 诊断包导出服务
+type Service struct {
+	opts    Options
+	http    *http.Client
+	now     func() time.Time
+	started time.Time
+
+	mu sync.Mutex
+}
+
+// NewService 创建诊断包导出服务
+func NewService(opts Options) *Service {
+	return &Service{
+		opts:    opts,
+		http:    &http.Client{Timeout: uploadTimeout},
+		now:     time.Now,
+		started: time.Now(),
+	}
+}
+
+// SetConfig 配置热加载后更新导出的配置和上传参数
+func (s *Service) SetConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts.Config = cfg
+}
+
+func (s *Service) config() *config.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opts.Config
+}
+
+// collector 诊断包中的一个文件
+type collector struct {
+	name    string
+	collect func(w io.Writer) error
+}
+
+// Write 将诊断包写入 w，单项收集失败不影响其他项，失败原因写入 errors.txt
+func (s *Service) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	collectors := []collector{
+		{"version.json", s.writeVersion},
+		{"config.toml", s.writeConfig},
+		{"system/system_info.json", jsonCollector(func() (any, error) { return system.GetSystemInfo() })},
+		{"system/host_info.json", jsonCollector(func() (any, error) { return system.GetHostInfo() })},
+		{"system/network_interfaces.json", jsonCollector(func() (any, error) { return system.GetNetworkInterfaces() })},
+		{"storage.json", s.writeStorage},
+		{"goroutines.txt", func(w io.Writer) error { return pprof.Lookup("goroutine").WriteTo(w, 2) }},
+	}
+
+	// 日志异步写入，先等待已记录的日志落盘，否则会缺少最近的日志
+	var failures []string
+	if err := logger.Flush(logFlushTimeout); err != nil {
+		failures = append(failures, fmt.Sprintf("logs: %v", err))
+	}
+	for _, name := range s.logNames() {
+		collectors = append(collectors, collector{"logs/" + name, s.logCollector(name)})
+	}
+
+	for _, c := range collectors {
+		// 先写入内存，失败的项不出现在压缩包中
+		var buf bytes.Buffer
+		if err := safeCollect(c, &buf); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				failures = append(failures, fmt.Sprintf("%s: %v", c.name, err))
+			}
+			continue
+		}
+		if err := writeEntry(zw, c.name, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		if err := writeEntry(zw, "errors.txt", []byte(strings.Join(failures, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// safeCollect 收集单项信息，存储未初始化等情况下的 panic 转为错误
+func safeCollect(c collector, w io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("收集时发生异常: %v", r)
+		}
+	}()
+	return c.collect(w)
+}
+
+func writeEntry(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func jsonCollector(fn func() (any, error)) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		v, err := fn()
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, v)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeVersion 写入版本和运行时信息
+func (s *Service) writeVersion(w io.Writer) error {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	info := map[string]any{
+		"version":     s.opts.Version,
+		"client_id":   s.opts.ClientID,
+		"go_version":  runtime.Version(),
+		"os":          runtime.GOOS,
+		"arch":        runtime.GOARCH,
+		"created_at":  s.now(),
+		"uptime":      s.now().Sub(s.started).Round(time.Second).String(),
+		"goroutines":  runtime.NumGoroutine(),
+		"heap_alloc":  mem.HeapAlloc,
+		"sys_memory":  mem.Sys,
+		"num_gc":      mem.NumGC,
+		"build":       buildSettings(),
+		"working_dir": workingDir(),
+	}
+	return writeJSON(w, info)
+}
+
+// buildSettings 返回编译信息中的版本控制字段
+func buildSettings() map[string]string {
+	settings := make(map[string]string)
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return settings
+	}
+	settings["main"] = bi.Main.Version
+	for _, s := range bi.Settings {
+		if strings.HasPrefix(s.Key, "vcs.") || s.Key == "GOOS" || s.Key == "GOARCH" {
+			settings[s.Key] = s.Value
+		}
+	}
+	return settings
+}
+
+func workingDir() string {
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// writeConfig 写入生效的配置，敏感配置项已隐藏
+func (s *Service) writeConfig(w io.Writer) error {
+	cfg := s.config()
+	if cfg == nil {
+		return errors.New("配置未加载")
+	}
+	data, err := toml.Marshal(cfg.Redacted())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// storageEntry 存储中的一条数据，只记录大小不导出内容
+type storageEntry struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Size      int       `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// writeStorage 写入存储统计
+func (s *Service) writeStorage(w io.Writer) error {
+	stats := map[string]any{
+		"dir": s.opts.StorageDir,
+	}
+
+	if size, files, err := dirSize(s.opts.StorageDir); err == nil {
+		stats["disk_bytes"] = size
+		stats["disk_files"] = files
+	} else {
+		stats["disk_error"] = err.Error()
+	}
+
+	if s.opts.Store != nil {
+		list, err := s.opts.Store.List()
+		if err != nil {
+			stats["list_error"] = err.Error()
+		} else {
+			entries := make([]storageEntry, 0, len(list))
+			for _, e := range list {
+				data, _ := json.Marshal(e.Data)
+				entries = append(entries, storageEntry{ID: e.ID, Type: e.Type, Size: len(data), UpdatedAt: e.UpdatedAt})
+			}
+			stats["entries"] = entries
+			stats["count"] = len(entries)
+		}
+	}
+	return writeJSON(w, stats)
+}
+
+func dirSize(dir string) (size int64, files int, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		files++
+		return nil
+	})
+	return size, files, err
+}
+
+// logNames 返回要打包的日志文件：当前文件和每个日志最近的轮转文件
+func (s *Service) logNames() []string {
+	names := append([]string(nil), logFiles...)
+	entries, err := os.ReadDir(s.opts.LogDir)
+	if err != nil {
+		return names
+	}
+
+	backups := make(map[string][]logger.Backup)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if b, ok := logger.ParseBackup(entry.Name()); ok {
+			backups[b.Base] = append(backups[b.Base], b)
+		}
+	}
+	for _, name := range logFiles {
+		list := backups[strings.TrimSuffix(name, ".log")]
+		// 从新到旧
+		sort.Slice(list, func(i, j int) bool { return list[j].Before(list[i]) })
+		for _, b := range list[:min(len(list), logBackups)] {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
+
+// logCollector 读取日志文件最后 log_max_mb 的内容，从完整的一行开始
+// 压缩的轮转文件无法截取，超过上限时不打包
+func (s *Service) logCollector(name string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		limit := int64(config.Default().Diagnostics.LogMaxMB) << 20
+		if cfg := s.config(); cfg != nil {
+			limit = int64(cfg.Diagnostics.LogMaxMB) << 20
+		}
+
+		f, err := os.Open(filepath.Join(s.opts.LogDir, name))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() <= limit {
+			_, err = io.Copy(w, f)
+			return err
+		}
+		if strings.HasSuffix(name, ".gz") {
+			return fmt.Errorf("文件大小 %d 字节超过上限，未打包", info.Size())
+		}
+
+		if _, err := f.Seek(info.Size()-limit, io.SeekStart); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
+
+// Export 在 dir 中生成诊断包，返回文件路径
+func (s *Service) Export(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("diagnostics-%s.zip", s.now().Format(fileTimeFormat))
+	if s.opts.ClientID != "" {
+		name = fmt.Sprintf("diagnostics-%s-%s.zip", s.opts.ClientID, s.now().Format(fileTimeFormat))
+	}
+	path := filepath.Join(dir, name)
+
+	// 先写临时文件，避免留下不完整的压缩包
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.Write(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CanUpload 是否配置了上传地址
+func (s *Service) CanUpload() bool {
+	cfg := s.config()
+	return cfg != nil && cfg.Diagnostics.UploadURL != ""
+}
+
+// Upload 将诊断包上传到 diagnostics.upload_url，未配置地址时返回错误
+func (s *Service) Upload(ctx context.Context, path string) error {
+	if !s.CanUpload() {
+		return errors.New("未配置诊断包上传地址")
+	}
+	cfg := s.config().Diagnostics
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.UploadURL, f)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("X-Client-ID", s.opts.ClientID)
+	req.Header.Set("X-File-Name", filepath.Base(path))
+	if cfg.UploadToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.UploadToken)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("上传诊断包失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("上传诊断包失败: HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/logger"
+	"sw_call/pkg/storage"
+)
+
+func newTestService(t *testing.T) (*Service, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	storageDir := filepath.Join(dir, "storage")
+	store, err := storage.InitDataStore(storageDir)
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	assert.NoError(t, store.Save(&storage.DataEntry{ID: "forward_url", Data: "http://10.0.0.1:8080"}))
+
+	logDir := filepath.Join(dir, "logs")
+	assert.NoError(t, os.MkdirAll(logDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(logDir, "info.log"), []byte("{\"msg\":\"开诊\"}\n"), 0o644))
+
+	cfg := config.Default()
+	cfg.Diagnostics.UploadToken = "s3cret"
+
+	s := NewService(Options{
+		Version:    "1.0.0",
+		ClientID:   "client-1",
+		Config:     cfg,
+		Store:      store,
+		StorageDir: storageDir,
+		LogDir:     logDir,
+	})
+	s.now = func() time.Time { return time.Date(2024, 6, 1, 9, 30, 0, 0, time.Local) }
+	return s, dir
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestWrite(t *testing.T) {
+	s, _ := newTestService(t)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.Write(&buf))
+	files := readZip(t, buf.Bytes())
+
+	for _, name := range []string{"version.json", "config.toml", "storage.json", "goroutines.txt", "logs/info.log"} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "logs/debug.log", "不存在的日志文件跳过")
+
+	assert.Contains(t, files["version.json"], `"client_id": "client-1"`)
+	assert.Contains(t, files["config.toml"], "upload_token = '******'")
+	assert.NotContains(t, files["config.toml"], "s3cret")
+	assert.Contains(t, files["storage.json"], `"id": "forward_url"`)
+	assert.NotContains(t, files["storage.json"], "10.0.0.1", "不导出存储内容")
+	assert.Contains(t, files["goroutines.txt"], "goroutine")
+	assert.Contains(t, files["logs/info.log"], "开诊")
+}
+
+func TestWriteFlushesLogs(t *testing.T) {
+	s, dir := newTestService(t)
+	defer slog.SetDefault(slog.Default())
+	defer logger.Close(time.Second)
+
+	// 异步写入的日志在打包前落盘
+	logger.NewMultiLevelHandler(logger.Config{Path: filepath.Join(dir, "logs"), Level: slog.LevelInfo, Output: logger.OutputFile, QueueSize: 16, Overflow: logger.OverflowBlock})
+	slog.Info("刚刚记录")
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.Write(&buf))
+	assert.Contains(t, readZip(t, buf.Bytes())["logs/info.log"], "刚刚记录")
+}
+
+func TestWriteLogBackups(t *testing.T) {
+	s, dir := newTestService(t)
+	for _, name := range []string{"info-2024-05-30.log", "info-2024-05-31.log.gz", "info-2024-05-31.1.log", "error-2024-05-01.log", "diagnostics-client-1.zip"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "logs", name), []byte(name), 0o644))
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.Write(&buf))
+	files := readZip(t, buf.Bytes())
+
+	// 每个日志只打包最近的两个轮转文件
+	assert.Contains(t, files, "logs/info-2024-05-31.1.log")
+	assert.Contains(t, files, "logs/info-2024-05-31.log.gz")
+	assert.NotContains(t, files, "logs/info-2024-05-30.log")
+	assert.Contains(t, files, "logs/error-2024-05-01.log")
+	assert.NotContains(t, files, "logs/diagnostics-client-1.zip")
+}
+
+func TestLogTail(t *testing.T) {
+	s, dir := newTestService(t)
+	s.opts.Config.Diagnostics.LogMaxMB = 1
+
+	line := strings.Repeat("x", 1023) + "\n"
+	content := "第一行\n" + strings.Repeat(line, 1100)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "logs", "debug.log"), []byte(content), 0o644))
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.logCollector("debug.log")(&buf))
+	assert.LessOrEqual(t, buf.Len(), 1<<20)
+	assert.True(t, strings.HasPrefix(buf.String(), line), "从完整的一行开始")
+	assert.NotContains(t, buf.String(), "第一行")
+}
+
+func TestCollectFailure(t *testing.T) {
+	s, _ := newTestService(t)
+	s.opts.Store = &storage.DataStore{}
+	s.opts.Config = nil
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.Write(&buf))
+	files := readZip(t, buf.Bytes())
+	assert.NotContains(t, files, "config.toml")
+	assert.Contains(t, files["errors.txt"], "config.toml: 配置未加载")
+	assert.Contains(t, files["errors.txt"], "storage.json: 收集时发生异常")
+}
+
+func TestExportAndUpload(t *testing.T) {
+	s, dir := newTestService(t)
+
+	path, err := s.Export(filepath.Join(dir, "diagnostics"))
+	assert.NoError(t, err)
+	assert.Equal(t, "diagnostics-client-1-20240601-093000.zip", filepath.Base(path))
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1, "不留下临时文件")
+
+	assert.ErrorContains(t, s.Upload(context.Background(), path), "未配置")
+
+	var received []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		received, _ = io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	s.opts.Config.Diagnostics.UploadURL = server.URL
+	assert.NoError(t, s.Upload(context.Background(), path))
+	assert.Equal(t, "client-1", header.Get("X-Client-ID"))
+	assert.Equal(t, "application/zip", header.Get("Content-Type"))
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, data, received)
+
+	s.opts.Config.Diagnostics.UploadToken = "wrong"
+	assert.ErrorContains(t, s.Upload(context.Background(), path), "HTTP 401")
+}
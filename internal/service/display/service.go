@@ -0,0 +1,133 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"sw_call/internal/config"
+	"sw_call/internal/event"
+	"sw_call/internal/service/announce"
+	"sw_call/pkg/ledsign"
+	"sw_call/pkg/serial"
+)
+
+// Opener 打开输出设备
+type Opener func() (io.WriteCloser, error)
+
+// Service 门头 LED 屏显示服务
+type Service struct {
+	cfg      config.DisplayConfig
+	protocol ledsign.Protocol
+	open     Opener
+
+	mu   sync.Mutex
+	port io.WriteCloser
+}
+
+// NewService 根据配置创建显示服务
+func NewService(cfg config.DisplayConfig) (*Service, error) {
+	return newService(cfg, func() (io.WriteCloser, error) {
+		return serial.Open(serial.Config{Name: cfg.Port, BaudRate: cfg.BaudRate})
+	})
+}
+
+func newService(cfg config.DisplayConfig, open Opener) (*Service, error) {
+	header, err := ledsign.ParseHex(cfg.Header)
+	if err != nil {
+		return nil, fmt.Errorf("display.header 格式错误: %w", err)
+	}
+	footer, err := ledsign.ParseHex(cfg.Footer)
+	if err != nil {
+		return nil, fmt.Errorf("display.footer 格式错误: %w", err)
+	}
+
+	protocol := ledsign.Protocol{
+		Header:      header,
+		Footer:      footer,
+		Address:     cfg.Address,
+		LengthBytes: cfg.LengthBytes,
+		Checksum:    ledsign.Checksum(cfg.Checksum),
+		Encoding:    cfg.Encoding,
+	}
+	// 提前编码一次，尽早发现配置错误
+	if _, err := protocol.Encode(""); err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		cfg:      cfg,
+		protocol: protocol,
+		open:     open,
+	}, nil
+}
+
+// Subscribe 订阅呼叫、过号、结诊事件
+func (s *Service) Subscribe(bus *event.Bus) {
+	bus.Subscribe("display", s.Handle, event.TypeCall, event.TypePass, event.TypeEnd)
+}
+
+// Handle 按事件类型渲染文本并发送到屏幕
+func (s *Service) Handle(e event.Event) error {
+	var template string
+	switch e.Type {
+	case event.TypeCall:
+		template = s.cfg.CallTemplate
+	case event.TypePass:
+		template = s.cfg.PassTemplate
+	case event.TypeEnd:
+		template = s.cfg.EndTemplate
+	default:
+		return nil
+	}
+
+	text := announce.Render(template, announce.Call{Number: e.Number, Name: e.Name, Room: e.Room})
+	if err := s.Show(text); err != nil {
+		return err
+	}
+	// 显示文本含患者姓名，日志只记录排队号和诊室
+	slog.Debug("LED 屏显示", "type", e.Type, "number", e.Number, "room", e.Room)
+	return nil
+}
+
+// Show 在屏幕上显示文本
+func (s *Service) Show(text string) error {
+	frame, err := s.protocol.Encode(text)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 写入失败时重新打开一次串口，应对设备拔插
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.port == nil {
+			if s.port, err = s.open(); err != nil {
+				return fmt.Errorf("打开串口 %s 失败: %w", s.cfg.Port, err)
+			}
+		}
+
+		if _, err = s.port.Write(frame); err == nil {
+			return nil
+		}
+
+		s.port.Close()
+		s.port = nil
+	}
+	return fmt.Errorf("写入串口 %s 失败: %w", s.cfg.Port, err)
+}
+
+// Close 关闭串口
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.port == nil {
+		return nil
+	}
+	err := s.port.Close()
+	s.port = nil
+	return err
+}
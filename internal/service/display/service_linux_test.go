@@ -0,0 +1,47 @@
+package display
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"sw_call/internal/config"
+	"sw_call/internal/event"
+	"sw_call/pkg/serial"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 伪终端只在 Linux 上可用，serial.OpenPTY 也只在 serial_linux.go 中定义
+func TestServiceOverPTY(t *testing.T) {
+	master, slave, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("无法打开伪终端: %v", err)
+	}
+	defer master.Close()
+
+	cfg := config.Default().Display
+	cfg.Port = slave
+	cfg.Header = "AA"
+	cfg.Footer = "55"
+	cfg.Address = -1
+	cfg.LengthBytes = 0
+	cfg.Checksum = "none"
+
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+	defer svc.Close()
+
+	bus := event.NewBus()
+	svc.Subscribe(bus)
+	bus.Publish(event.Event{Type: event.TypeCall, Number: "12", Name: "张三"})
+
+	// "12号 张三" 的 GB2312 编码
+	want := []byte{0xAA, '1', '2', 0xBA, 0xC5, ' ', 0xD5, 0xC5, 0xC8, 0xFD, 0x55}
+
+	got := make([]byte, len(want))
+	master.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadFull(master, got)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
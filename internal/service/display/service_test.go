@@ -0,0 +1,64 @@
+package display
+
+import (
+	"io"
+	"testing"
+
+	"sw_call/internal/config"
+	"sw_call/internal/event"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriter 第一次写入失败，模拟设备被拔出
+type failingWriter struct {
+	fail    bool
+	written [][]byte
+	closed  bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, io.ErrClosedPipe
+	}
+	w.written = append(w.written, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (w *failingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestServiceReopensOnWriteError(t *testing.T) {
+	broken := &failingWriter{fail: true}
+	healthy := &failingWriter{}
+	opened := 0
+
+	cfg := config.Default().Display
+	svc, err := newService(cfg, func() (io.WriteCloser, error) {
+		opened++
+		if opened == 1 {
+			return broken, nil
+		}
+		return healthy, nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Handle(event.Event{Type: event.TypePass, Number: "3"}))
+	assert.True(t, broken.closed)
+	assert.Len(t, healthy.written, 1)
+	assert.Equal(t, 2, opened)
+}
+
+func TestServiceInvalidConfig(t *testing.T) {
+	cfg := config.Default().Display
+	cfg.Checksum = "md5"
+	_, err := NewService(cfg)
+	assert.Error(t, err)
+
+	cfg = config.Default().Display
+	cfg.Header = "ZZ"
+	_, err = NewService(cfg)
+	assert.Error(t, err)
+}
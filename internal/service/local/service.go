@@ -1,8 +1,6 @@
 package local
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"log/slog"
 	"sw_call/pkg/storage"
 )
@@ -19,36 +17,6 @@ func NewService(store *storage.DataStore) *Service {
 	}
 }
 
-// LoadClientID 加载客户端ID
-func (s *Service) LoadClientID() *Response {
-	entry, err := s.store.Load("client_id")
-	if err != nil {
-		// 如果不存在，创建新的客户端ID
-		newID := generateClientID()
-		newEntry := &storage.DataEntry{
-			ID:   "client_id",
-			Type: "config",
-			Data: newID,
-		}
-		if saveErr := s.store.Save(newEntry); saveErr != nil {
-			slog.Error("保存客户端ID失败", "error", saveErr)
-			return NewErrorResponse("生成客户端ID失败")
-		}
-		return NewSuccessResponse(newID)
-	}
-	if entry.Data == nil {
-		// 数据为空，重新生成
-		newID := generateClientID()
-		entry.Data = newID
-		if saveErr := s.store.Save(entry); saveErr != nil {
-			slog.Error("保存客户端ID失败", "error", saveErr)
-			return NewErrorResponse("生成客户端ID失败")
-		}
-		return NewSuccessResponse(newID)
-	}
-	return NewSuccessResponse(entry.Data)
-}
-
 // SaveForwardURL 保存服务器地址
 func (s *Service) SaveForwardURL(url string) *Response {
 	if url == "" {
@@ -147,14 +115,3 @@ func (s *Service) GetLocaldataList() *Response {
 
 	return NewSuccessResponse(entries)
 }
-
-// generateClientID 生成客户端ID
-func generateClientID() string {
-	bytes := make([]byte, 8)
-	if _, err := rand.Read(bytes); err != nil {
-		slog.Error("生成随机ID失败", "error", err)
-		// 降级方案：使用时间戳
-		return "client_" + string(rune(123456789))
-	}
-	return hex.EncodeToString(bytes)
-}
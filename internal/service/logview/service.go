@@ -0,0 +1,375 @@
+package logview
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"sw_call/pkg/logger"
+)
+
+const (
+	// DefaultPageSize 默认每页条数
+	DefaultPageSize = 100
+	// MaxPageSize 每页最大条数
+	MaxPageSize = 1000
+
+	// maxLineSize 单行日志最大长度，前端上报的堆栈可能较长
+	maxLineSize = 1 << 20
+)
+
+// timeFormats 日志中可能出现的时间格式，MultiLevelHandler 使用 time.Time.String()
+var timeFormats = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.RFC3339Nano,
+}
+
+// Entry 一条日志
+type Entry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Source  string         `json:"source"`
+	Attrs   map[string]any `json:"attrs"`
+	File    string         `json:"file"`
+}
+
+// Query 查询条件，零值字段表示不过滤
+type Query struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Level    string            `json:"level"`    // 最低级别
+	Contains string            `json:"contains"` // 消息包含的文本，不区分大小写
+	Attrs    map[string]string `json:"attrs"`    // 属性过滤，键支持 call.number 形式的分组路径
+	Page     int               `json:"page"`     // 从 1 开始
+	PageSize int               `json:"pageSize"`
+}
+
+// Result 查询结果，按时间倒序
+type Result struct {
+	Entries  []Entry `json:"entries"`
+	Total    int     `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"pageSize"`
+}
+
+// Service 读取 MultiLevelHandler 写入的 JSON 日志
+type Service struct {
+	dir string
+}
+
+// NewService 创建日志查询服务，dir 为日志目录
+func NewService(dir string) *Service {
+	return &Service{dir: dir}
+}
+
+// matcher 编译后的查询条件
+type matcher struct {
+	from, to time.Time
+	level    slog.Level
+	contains string
+	attrs    map[string]string
+}
+
+func newMatcher(q Query) (*matcher, error) {
+	m := &matcher{
+		from:     q.From,
+		to:       q.To,
+		level:    slog.LevelDebug,
+		contains: strings.ToLower(q.Contains),
+		attrs:    q.Attrs,
+	}
+	if q.Level != "" {
+		level, err := logger.ParseLevel(q.Level)
+		if err != nil {
+			return nil, err
+		}
+		m.level = level
+	}
+	return m, nil
+}
+
+// Validate 检查查询条件
+func (q Query) Validate() error {
+	_, err := newMatcher(q)
+	return err
+}
+
+// match 判断日志是否满足条件
+func (m *matcher) match(e *Entry, level slog.Level) bool {
+	if level < m.level {
+		return false
+	}
+	if !m.from.IsZero() && e.Time.Before(m.from) {
+		return false
+	}
+	if !m.to.IsZero() && e.Time.After(m.to) {
+		return false
+	}
+	if m.contains != "" && !strings.Contains(strings.ToLower(e.Message), m.contains) {
+		return false
+	}
+	for key, want := range m.attrs {
+		value, ok := lookupAttr(e.Attrs, key)
+		if !ok || fmt.Sprint(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// baseName 按最低级别选择日志文件，级别越高文件越小
+func baseName(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
+
+// Query 按条件查询日志，包含轮转的旧文件（含 .gz）
+func (s *Service) Query(q Query) (*Result, error) {
+	m, err := newMatcher(q)
+	if err != nil {
+		return nil, err
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	q.PageSize = min(q.PageSize, MaxPageSize)
+
+	files, err := s.files(baseName(m.level))
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一遍只统计总数，内存占用与页码无关
+	total := 0
+	if err := m.scan(files, func(*Entry) { total++ }); err != nil {
+		return nil, err
+	}
+	result := &Result{Total: total, Page: q.Page, PageSize: q.PageSize}
+	// 先比较页数，避免页码过大时 offset 溢出
+	if q.Page-1 >= (total+q.PageSize-1)/q.PageSize {
+		return result, nil
+	}
+
+	// 第二遍按时间顺序保留本页对应的 [start, end) 条，扫描期间新写入的日志在 end 之后，不影响分页
+	end := total - (q.Page-1)*q.PageSize
+	start := max(end-q.PageSize, 0)
+	index := 0
+	entries := make([]Entry, 0, end-start)
+	err = m.scan(files, func(e *Entry) {
+		if index >= start && index < end {
+			entries = append(entries, *e)
+		}
+		index++
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+	result.Entries = entries
+	return result, nil
+}
+
+// scan 按时间顺序读取日志文件，对满足条件的日志调用 fn
+func (m *matcher) scan(files []logFile, fn func(*Entry)) error {
+	for _, f := range files {
+		// 文件的最后修改时间早于开始时间，其中的日志都不满足条件
+		if !m.from.IsZero() && f.modTime.Before(m.from) {
+			continue
+		}
+		err := scanFile(f.path, func(e *Entry, level slog.Level) {
+			if m.match(e, level) {
+				fn(e)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("读取日志文件 %s 失败: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// logFile 日志文件及其最后修改时间
+type logFile struct {
+	path    string
+	modTime time.Time
+	backup  logger.Backup
+}
+
+// files 返回 name.log 及其轮转文件，按时间从旧到新排列
+func (s *Service) files(name string) ([]logFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []logFile
+	var current *logFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		f := logFile{path: filepath.Join(s.dir, entry.Name()), modTime: info.ModTime()}
+
+		if entry.Name() == name+".log" {
+			current = &f
+			continue
+		}
+		// 轮转文件名形如 debug-2024-06-01.log、debug-2024-06-01.1.log.gz
+		backup, ok := logger.ParseBackup(entry.Name())
+		if !ok || backup.Base != name {
+			continue
+		}
+		f.backup = backup
+		files = append(files, f)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].backup.Before(files[j].backup) })
+	if current != nil {
+		files = append(files, *current)
+	}
+	return files, nil
+}
+
+// scanFile 逐行解析日志文件，无法解析的行跳过
+func scanFile(path string, fn func(*Entry, slog.Level)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	_, err = scanLines(r, filepath.Base(path), fn)
+	return err
+}
+
+// scanLines 解析 r 中的完整行，返回已解析的字节数，末尾不完整的行不计入
+func scanLines(r io.Reader, name string, fn func(*Entry, slog.Level)) (int64, error) {
+	reader := bufio.NewReaderSize(r, maxLineSize)
+	var n int64
+	for {
+		line, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// 超过 maxLineSize 的行整行跳过
+			skipped := int64(len(line))
+			for err == bufio.ErrBufferFull {
+				line, err = reader.ReadSlice('\n')
+				skipped += int64(len(line))
+			}
+			if err != nil {
+				return n, ignoreEOF(err)
+			}
+			n += skipped
+			continue
+		}
+		if err != nil {
+			return n, ignoreEOF(err)
+		}
+		n += int64(len(line))
+		if e, level, ok := parseLine(line); ok {
+			e.File = name
+			fn(e, level)
+		}
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// parseLine 解析一行 JSON 日志
+func parseLine(line []byte) (*Entry, slog.Level, bool) {
+	var raw map[string]any
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return nil, 0, false
+	}
+
+	e := &Entry{}
+	if s, ok := raw[slog.TimeKey].(string); ok {
+		e.Time = parseTime(s)
+	}
+	var level slog.Level
+	if s, ok := raw[slog.LevelKey].(string); ok {
+		if err := level.UnmarshalText([]byte(s)); err != nil {
+			return nil, 0, false
+		}
+	}
+	e.Level = logger.LevelName(level)
+	e.Message, _ = raw[slog.MessageKey].(string)
+
+	switch source := raw[slog.SourceKey].(type) {
+	case string:
+		e.Source = source
+	case map[string]any:
+		e.Source = fmt.Sprintf("%v:%v", source["file"], source["line"])
+	}
+
+	for _, key := range []string{slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey} {
+		delete(raw, key)
+	}
+	e.Attrs = raw
+	return e, level, true
+}
+
+func parseTime(s string) time.Time {
+	for _, layout := range timeFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// lookupAttr 按分组路径查找属性
+func lookupAttr(attrs map[string]any, path string) (any, bool) {
+	if value, ok := attrs[path]; ok {
+		return value, true
+	}
+	var current any = attrs
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,201 @@
+package logview
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+// line 生成一行 MultiLevelHandler 格式的日志
+func line(minute int, level, msg string, attrs string) string {
+	t := base.Add(time.Duration(minute) * time.Minute)
+	s := fmt.Sprintf(`{"time":%q,"level":%q,"source":{"function":"main.f","file":"sw_call/app.go","line":%d},"msg":%q`, t.String(), level, minute, msg)
+	if attrs != "" {
+		s += "," + attrs
+	}
+	return s + "}\n"
+}
+
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+}
+
+func setupLogs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	// 轮转的旧文件：升级前 lumberjack 压缩的文件和按日期命名的文件
+	writeGzip(t, filepath.Join(dir, "debug-2024-06-01T09-01-30.000.log.gz"),
+		line(0, "INFO", "开诊", `"doc_id":7`)+line(1, "DEBUG", "轮询", ""))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "debug-2024-06-01.log"),
+		[]byte(line(2, "WARN", "呼叫超时", `"doc_id":8,"call":{"number":12}`)+"不是 JSON\n"+line(3, "ERROR", "获取患者列表失败", `"source":"frontend"`)), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"),
+		[]byte(line(4, "INFO", "呼叫患者", `"doc_id":7,"call":{"number":13}`)+line(5, "ERROR+4", "进程退出", "")), 0o644))
+	// 其他文件不读取
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "info.log"), []byte(line(9, "INFO", "info 文件", "")), 0o644))
+	return dir
+}
+
+func messages(entries []Entry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+func TestQuery(t *testing.T) {
+	s := NewService(setupLogs(t))
+
+	result, err := s.Query(Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, result.Total)
+	assert.Equal(t, []string{"进程退出", "呼叫患者", "获取患者列表失败", "呼叫超时", "轮询", "开诊"}, messages(result.Entries))
+
+	latest := result.Entries[0]
+	assert.Equal(t, "fatal", latest.Level)
+	assert.Equal(t, "debug.log", latest.File)
+	assert.Equal(t, "sw_call/app.go:5", latest.Source)
+	assert.True(t, base.Add(5*time.Minute).Equal(latest.Time))
+	assert.Equal(t, "frontend", result.Entries[2].Source)
+	assert.Equal(t, "debug-2024-06-01T09-01-30.000.log.gz", result.Entries[5].File)
+
+	// 级别、文本、属性过滤
+	result, err = s.Query(Query{Level: "warn", Contains: "患者"})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Entries, "warn 及以上从 info.log 读取")
+
+	result, err = s.Query(Query{Contains: "呼叫", Attrs: map[string]string{"doc_id": "7"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"呼叫患者"}, messages(result.Entries))
+
+	result, err = s.Query(Query{Attrs: map[string]string{"call.number": "12"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"呼叫超时"}, messages(result.Entries))
+
+	// 时间范围
+	result, err = s.Query(Query{From: base.Add(90 * time.Second), To: base.Add(4 * time.Minute)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"呼叫患者", "获取患者列表失败", "呼叫超时"}, messages(result.Entries))
+
+	_, err = s.Query(Query{Level: "verbose"})
+	assert.Error(t, err)
+}
+
+func TestQueryLevelFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "error.log"), []byte(line(0, "ERROR", "错误", "")+line(1, "ERROR+4", "致命", "")), 0o644))
+
+	result, err := NewService(dir).Query(Query{Level: "fatal"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"致命"}, messages(result.Entries))
+
+	// 目录不存在时返回空结果
+	result, err = NewService(filepath.Join(dir, "missing")).Query(Query{})
+	assert.NoError(t, err)
+	assert.Zero(t, result.Total)
+}
+
+func TestQueryPagination(t *testing.T) {
+	dir := t.TempDir()
+	var b strings.Builder
+	for i := 0; i < 25; i++ {
+		b.WriteString(line(i, "INFO", fmt.Sprintf("第%d条", i), ""))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte(b.String()), 0o644))
+	s := NewService(dir)
+
+	result, err := s.Query(Query{Page: 2, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 25, result.Total)
+	assert.Len(t, result.Entries, 10)
+	assert.Equal(t, "第14条", result.Entries[0].Message)
+	assert.Equal(t, "第5条", result.Entries[9].Message)
+
+	result, err = s.Query(Query{Page: 3, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"第4条", "第3条", "第2条", "第1条", "第0条"}, messages(result.Entries))
+
+	result, err = s.Query(Query{Page: 4, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Entries)
+	assert.Equal(t, 25, result.Total)
+
+	// 页码过大时不按页码分配内存
+	result, err = s.Query(Query{Page: math.MaxInt, PageSize: MaxPageSize})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Entries)
+	assert.Equal(t, 25, result.Total)
+}
+
+func TestTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "debug.log")
+	assert.NoError(t, os.WriteFile(path, []byte(line(0, "INFO", "开始前", "")), 0o644))
+
+	var mu sync.Mutex
+	var got []string
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- NewService(dir).Tail(ctx, Query{Contains: "呼叫"}, 5*time.Millisecond, func(entries []Entry) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, messages(entries)...)
+		})
+	}()
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), got...)
+	}
+
+	appendLog := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		assert.NoError(t, err)
+		_, err = f.WriteString(s)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	// 不完整的行等写完后再读取
+	full := line(1, "INFO", "呼叫患者", "")
+	appendLog(line(1, "INFO", "轮询", "") + full[:20])
+	time.Sleep(20 * time.Millisecond)
+	appendLog(full[20:])
+	assert.Eventually(t, func() bool { return len(received()) == 1 }, time.Second, 5*time.Millisecond)
+
+	// 轮转后从新文件开头读取
+	assert.NoError(t, os.Rename(path, filepath.Join(dir, "debug-2024-06-01.log")))
+	assert.NoError(t, os.WriteFile(path, []byte(line(2, "WARN", "重新呼叫", "")), 0o644))
+	assert.Eventually(t, func() bool { return len(received()) == 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, []string{"呼叫患者", "重新呼叫"}, received())
+}
+
+func TestQueryValidate(t *testing.T) {
+	assert.NoError(t, Query{}.Validate())
+	assert.NoError(t, Query{Level: "warn"}.Validate())
+	assert.Error(t, Query{Level: "verbose"}.Validate())
+}
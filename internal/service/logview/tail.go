@@ -0,0 +1,90 @@
+package logview
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTailInterval 实时日志的轮询间隔
+const DefaultTailInterval = time.Second
+
+// Tail 持续读取新写入的日志，满足条件的日志按批通过 emit 返回，直到 ctx 取消
+// 只返回开始之后写入的日志，检测到文件轮转后从新文件开头读取
+func (s *Service) Tail(ctx context.Context, q Query, interval time.Duration, emit func([]Entry)) error {
+	m, err := newMatcher(q)
+	if err != nil {
+		return err
+	}
+
+	t := &tailer{path: filepath.Join(s.dir, baseName(m.level)+".log"), matcher: m}
+	if info, err := os.Stat(t.path); err == nil {
+		t.info, t.offset = info, info.Size()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			entries, err := t.poll()
+			if err != nil {
+				slog.Debug("读取实时日志失败", "path", t.path, "error", err)
+				continue
+			}
+			if len(entries) > 0 {
+				emit(entries)
+			}
+		}
+	}
+}
+
+// tailer 记录已读取的位置
+type tailer struct {
+	path    string
+	matcher *matcher
+	info    os.FileInfo
+	offset  int64
+}
+
+// poll 读取上次位置之后的完整行
+func (t *tailer) poll() ([]Entry, error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// 文件被轮转（换了文件或变小）后从头读取
+	if t.info == nil || !os.SameFile(t.info, info) || info.Size() < t.offset {
+		t.offset = 0
+	}
+	t.info = info
+	if info.Size() == t.offset {
+		return nil, nil
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	n, err := scanLines(file, filepath.Base(t.path), func(e *Entry, level slog.Level) {
+		if t.matcher.match(e, level) {
+			entries = append(entries, *e)
+		}
+	})
+	t.offset += n
+	return entries, err
+}
@@ -0,0 +1,194 @@
+package preference
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"sw_call/internal/service/announce"
+)
+
+// Preferences 用户偏好设置
+type Preferences struct {
+	VoiceVolume         int     `json:"voice_volume"`          // 语音音量 0-100
+	AutoRefreshInterval int     `json:"auto_refresh_interval"` // 患者列表自动刷新间隔（秒）
+	FontScale           float64 `json:"font_scale"`            // 字体缩放比例
+	CallTemplate        string  `json:"call_template"`         // 呼叫播报模板
+	ConfirmBeforeEnd    bool    `json:"confirm_before_end"`    // 结束就诊前是否确认
+}
+
+// Defaults 返回默认偏好设置
+func Defaults() Preferences {
+	return Preferences{
+		VoiceVolume:         80,
+		AutoRefreshInterval: 30,
+		FontScale:           1.0,
+		CallTemplate:        announce.DefaultTemplate,
+		ConfirmBeforeEnd:    true,
+	}
+}
+
+// FieldType 字段类型
+type FieldType string
+
+const (
+	TypeInt    FieldType = "int"
+	TypeFloat  FieldType = "float"
+	TypeString FieldType = "string"
+	TypeBool   FieldType = "bool"
+)
+
+// Field 偏好字段定义
+type Field struct {
+	Key         string    `json:"key"`
+	Type        FieldType `json:"type"`
+	Description string    `json:"description"`
+	Default     any       `json:"default"`
+	Min         *float64  `json:"min,omitempty"`
+	Max         *float64  `json:"max,omitempty"`
+	MaxLength   int       `json:"max_length,omitempty"`
+
+	get func(p *Preferences) any
+	set func(p *Preferences, v any)
+}
+
+// schema 偏好字段定义表
+var schema = []*Field{
+	{
+		Key:         "voice_volume",
+		Type:        TypeInt,
+		Description: "语音播报音量",
+		Min:         bound(0),
+		Max:         bound(100),
+		get:         func(p *Preferences) any { return p.VoiceVolume },
+		set:         func(p *Preferences, v any) { p.VoiceVolume = v.(int) },
+	},
+	{
+		Key:         "auto_refresh_interval",
+		Type:        TypeInt,
+		Description: "患者列表自动刷新间隔（秒）",
+		Min:         bound(5),
+		Max:         bound(600),
+		get:         func(p *Preferences) any { return p.AutoRefreshInterval },
+		set:         func(p *Preferences, v any) { p.AutoRefreshInterval = v.(int) },
+	},
+	{
+		Key:         "font_scale",
+		Type:        TypeFloat,
+		Description: "字体缩放比例",
+		Min:         bound(0.8),
+		Max:         bound(2.0),
+		get:         func(p *Preferences) any { return p.FontScale },
+		set:         func(p *Preferences, v any) { p.FontScale = v.(float64) },
+	},
+	{
+		Key:         "call_template",
+		Type:        TypeString,
+		Description: "呼叫播报模板，支持 {number} {name} {room} 占位符",
+		MaxLength:   100,
+		get:         func(p *Preferences) any { return p.CallTemplate },
+		set:         func(p *Preferences, v any) { p.CallTemplate = v.(string) },
+	},
+	{
+		Key:         "confirm_before_end",
+		Type:        TypeBool,
+		Description: "结束就诊前是否弹出确认",
+		get:         func(p *Preferences) any { return p.ConfirmBeforeEnd },
+		set:         func(p *Preferences, v any) { p.ConfirmBeforeEnd = v.(bool) },
+	},
+}
+
+func init() {
+	defaults := Defaults()
+	for _, f := range schema {
+		f.Default = f.get(&defaults)
+	}
+}
+
+// Schema 返回偏好字段定义
+func Schema() []*Field {
+	return schema
+}
+
+// lookup 查找字段定义
+func lookup(key string) (*Field, bool) {
+	for _, f := range schema {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// normalize 将前端传入的值转换为字段类型并校验范围
+func (f *Field) normalize(value any) (any, error) {
+	switch f.Type {
+	case TypeInt:
+		n, ok := toFloat(value)
+		if !ok || n != math.Trunc(n) {
+			return nil, fmt.Errorf("%s: 必须为整数", f.Key)
+		}
+		if err := f.checkRange(n); err != nil {
+			return nil, err
+		}
+		return int(n), nil
+	case TypeFloat:
+		n, ok := toFloat(value)
+		if !ok {
+			return nil, fmt.Errorf("%s: 必须为数字", f.Key)
+		}
+		if err := f.checkRange(n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case TypeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: 必须为字符串", f.Key)
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil, fmt.Errorf("%s: 不能为空", f.Key)
+		}
+		if f.MaxLength > 0 && utf8.RuneCountInString(s) > f.MaxLength {
+			return nil, fmt.Errorf("%s: 长度不能超过 %d", f.Key, f.MaxLength)
+		}
+		return s, nil
+	case TypeBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: 必须为布尔值", f.Key)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%s: 未知的字段类型 %s", f.Key, f.Type)
+}
+
+func (f *Field) checkRange(n float64) error {
+	if f.Min != nil && n < *f.Min {
+		return fmt.Errorf("%s: 不能小于 %v", f.Key, *f.Min)
+	}
+	if f.Max != nil && n > *f.Max {
+		return fmt.Errorf("%s: 不能大于 %v", f.Key, *f.Max)
+	}
+	return nil
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
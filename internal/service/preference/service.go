@@ -0,0 +1,198 @@
+package preference
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"sw_call/pkg/storage"
+)
+
+// keyPrefix 偏好设置在存储中的键前缀，后接医生ID
+const keyPrefix = "preferences:"
+
+// Change 偏好变化事件
+type Change struct {
+	DoctorID int    `json:"doctor_id"`
+	Key      string `json:"key"`
+	Old      any    `json:"old"`
+	New      any    `json:"new"`
+}
+
+// Service 用户偏好服务
+type Service struct {
+	store *storage.DataStore
+
+	mu       sync.Mutex
+	onChange func(Change)
+}
+
+// NewService 创建用户偏好服务
+func NewService(store *storage.DataStore) *Service {
+	return &Service{store: store}
+}
+
+// OnChange 设置偏好变化回调
+func (s *Service) OnChange(fn func(Change)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Get 获取医生的偏好设置，未保存过时返回默认值
+func (s *Service) Get(doctorID int) (*Preferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(doctorID)
+}
+
+// Update 更新部分偏好设置，任一字段校验失败时不做任何修改
+func (s *Service) Update(doctorID int, changes map[string]any) (*Preferences, error) {
+	s.mu.Lock()
+	prefs, err := s.load(doctorID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	next := *prefs
+	var errs []error
+	for key, value := range changes {
+		field, ok := lookup(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: 未知的偏好项", key))
+			continue
+		}
+		normalized, err := field.normalize(value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		field.set(&next, normalized)
+	}
+	if len(errs) > 0 {
+		s.mu.Unlock()
+		return nil, errors.Join(errs...)
+	}
+
+	events, err := s.save(doctorID, prefs, &next)
+	onChange := s.onChange
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	notify(onChange, events)
+	return &next, nil
+}
+
+// Reset 将指定偏好项恢复默认值，未指定时恢复全部
+func (s *Service) Reset(doctorID int, keys ...string) (*Preferences, error) {
+	defaults := Defaults()
+
+	s.mu.Lock()
+	prefs, err := s.load(doctorID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	next := *prefs
+	if len(keys) == 0 {
+		next = defaults
+	}
+	for _, key := range keys {
+		field, ok := lookup(key)
+		if !ok {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("%s: 未知的偏好项", key)
+		}
+		field.set(&next, field.get(&defaults))
+	}
+
+	events, err := s.save(doctorID, prefs, &next)
+	onChange := s.onChange
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	notify(onChange, events)
+	return &next, nil
+}
+
+// load 读取偏好设置，缺失或非法的字段使用默认值
+func (s *Service) load(doctorID int) (*Preferences, error) {
+	prefs := Defaults()
+
+	entry, err := s.store.Load(storageKey(doctorID))
+	if err != nil || entry.Data == nil {
+		return &prefs, nil
+	}
+
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored map[string]any
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+
+	for key, value := range stored {
+		field, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		normalized, err := field.normalize(value)
+		if err != nil {
+			slog.Warn("偏好设置值无效，使用默认值", "doctor_id", doctorID, "key", key, "error", err)
+			continue
+		}
+		field.set(&prefs, normalized)
+	}
+
+	return &prefs, nil
+}
+
+// save 保存偏好设置并返回变化事件
+func (s *Service) save(doctorID int, prev, next *Preferences) ([]Change, error) {
+	var events []Change
+	for _, field := range schema {
+		oldValue, newValue := field.get(prev), field.get(next)
+		if oldValue != newValue {
+			events = append(events, Change{DoctorID: doctorID, Key: field.Key, Old: oldValue, New: newValue})
+		}
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	entry := &storage.DataEntry{
+		ID:   storageKey(doctorID),
+		Type: "preferences",
+		Data: next,
+	}
+	if err := s.store.Save(entry); err != nil {
+		return nil, fmt.Errorf("保存偏好设置失败: %w", err)
+	}
+
+	slog.Info("偏好设置已更新", "doctor_id", doctorID, "changes", len(events))
+	return events, nil
+}
+
+func notify(onChange func(Change), events []Change) {
+	if onChange == nil {
+		return
+	}
+	for _, event := range events {
+		onChange(event)
+	}
+}
+
+func storageKey(doctorID int) string {
+	return fmt.Sprintf("%s%d", keyPrefix, doctorID)
+}
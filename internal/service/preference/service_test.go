@@ -0,0 +1,92 @@
+package preference
+
+import (
+	"testing"
+
+	"sw_call/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T) *Service {
+	store, err := storage.InitDataStore(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return NewService(store)
+}
+
+func TestServiceDefaults(t *testing.T) {
+	svc := newTestService(t)
+
+	prefs, err := svc.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Defaults(), *prefs)
+}
+
+func TestServiceUpdate(t *testing.T) {
+	svc := newTestService(t)
+
+	var events []Change
+	svc.OnChange(func(c Change) { events = append(events, c) })
+
+	// 前端传入的数字均为 float64
+	prefs, err := svc.Update(1, map[string]any{
+		"voice_volume": float64(50),
+		"font_scale":   1.25,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 50, prefs.VoiceVolume)
+	assert.Equal(t, 1.25, prefs.FontScale)
+	assert.Len(t, events, 2)
+
+	// 按医生隔离
+	other, err := svc.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, other.VoiceVolume)
+
+	saved, err := svc.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, saved.VoiceVolume)
+}
+
+func TestServiceUpdateValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes map[string]any
+	}{
+		{"音量超出范围", map[string]any{"voice_volume": float64(101)}},
+		{"音量非整数", map[string]any{"voice_volume": 1.5}},
+		{"刷新间隔过小", map[string]any{"auto_refresh_interval": float64(1)}},
+		{"字体缩放过大", map[string]any{"font_scale": 3.0}},
+		{"模板为空", map[string]any{"call_template": "  "}},
+		{"类型错误", map[string]any{"confirm_before_end": "yes"}},
+		{"未知字段", map[string]any{"unknown": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			_, err := svc.Update(1, tt.changes)
+			assert.Error(t, err)
+
+			prefs, _ := svc.Get(1)
+			assert.Equal(t, Defaults(), *prefs)
+		})
+	}
+}
+
+func TestServiceReset(t *testing.T) {
+	svc := newTestService(t)
+
+	_, err := svc.Update(1, map[string]any{"voice_volume": float64(10), "confirm_before_end": false})
+	assert.NoError(t, err)
+
+	prefs, err := svc.Reset(1, "voice_volume")
+	assert.NoError(t, err)
+	assert.Equal(t, 80, prefs.VoiceVolume)
+	assert.False(t, prefs.ConfirmBeforeEnd)
+
+	prefs, err = svc.Reset(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Defaults(), *prefs)
+}
@@ -0,0 +1,162 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sw_call/internal/config"
+	"sw_call/internal/event"
+	"sw_call/pkg/escpos"
+)
+
+// defaultTimeout 未配置 timeout_ms 时的连接和写入超时，避免网络打印机无响应时一直阻塞
+const defaultTimeout = 3 * time.Second
+
+// Slip 排队凭条
+type Slip struct {
+	Room    string    `json:"room"`
+	Number  string    `json:"number"`
+	Name    string    `json:"name"`
+	VisitID string    `json:"visit_id"`
+	Time    time.Time `json:"time"`
+}
+
+// Service 凭条打印服务
+type Service struct {
+	cfg config.PrinterConfig
+}
+
+// NewService 创建凭条打印服务
+func NewService(cfg config.PrinterConfig) (*Service, error) {
+	switch cfg.Output {
+	case "tcp":
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("printer.address 不能为空")
+		}
+	case "file":
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("printer.file_path 不能为空")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的打印输出方式: %s", cfg.Output)
+	}
+	return &Service{cfg: cfg}, nil
+}
+
+// Subscribe 订阅转诊事件，患者转到其他诊室时打印新凭条
+func (s *Service) Subscribe(bus *event.Bus) {
+	bus.Subscribe("printer", s.printMove, event.TypeMove)
+}
+
+// printMove 按转诊后新分配的排队号打印凭条，没有新排队号时不打印，避免凭条上是原诊室的号
+func (s *Service) printMove(e event.Event) error {
+	if e.Number == "" {
+		return errors.New("转诊事件缺少新排队号，未打印凭条")
+	}
+	return s.Print(Slip{
+		Room:    e.Room,
+		Number:  e.Number,
+		Name:    e.Name,
+		VisitID: e.VisitID,
+		Time:    time.Now(),
+	})
+}
+
+// Print 渲染并打印凭条
+func (s *Service) Print(slip Slip) error {
+	data, err := s.Render(slip)
+	if err != nil {
+		return err
+	}
+
+	if err := s.write(data); err != nil {
+		return fmt.Errorf("打印凭条失败: %w", err)
+	}
+	slog.Info("打印凭条", "room", slip.Room, "number", slip.Number, "visit_id", slip.VisitID)
+	return nil
+}
+
+// Render 按模板渲染凭条为 ESC/POS 字节流
+func (s *Service) Render(slip Slip) ([]byte, error) {
+	if slip.Time.IsZero() {
+		slip.Time = time.Now()
+	}
+	replacer := strings.NewReplacer(
+		"{room}", slip.Room,
+		"{number}", slip.Number,
+		"{name}", slip.Name,
+		"{visit_id}", slip.VisitID,
+		"{time}", slip.Time.Format("2006-01-02 15:04"),
+	)
+
+	b := escpos.NewBuilder()
+	if s.cfg.Title != "" {
+		b.Align(escpos.AlignCenter).Bold(true).Size(2, 2).Line(replacer.Replace(s.cfg.Title))
+		b.Bold(false).Size(1, 1).Feed(1)
+	}
+
+	b.Align(escpos.AlignLeft)
+	for _, line := range s.cfg.Lines {
+		b.Line(replacer.Replace(line))
+	}
+
+	if s.cfg.QRCode && slip.VisitID != "" {
+		b.Feed(1).Align(escpos.AlignCenter).QRCode(slip.VisitID, 6).Feed(1)
+	}
+
+	if s.cfg.Footer != "" {
+		b.Align(escpos.AlignCenter).Line(replacer.Replace(s.cfg.Footer))
+	}
+
+	return b.Feed(3).Cut().Bytes()
+}
+
+// timeout 连接和写入网络打印机的超时，0 使用默认值
+func (s *Service) timeout() time.Duration {
+	if s.cfg.TimeoutMs <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(s.cfg.TimeoutMs) * time.Millisecond
+}
+
+// write 输出到网络打印机或文件
+func (s *Service) write(data []byte) error {
+	var w io.WriteCloser
+	var err error
+
+	switch s.cfg.Output {
+	case "tcp":
+		timeout := s.timeout()
+		conn, err := net.DialTimeout("tcp", s.cfg.Address, timeout)
+		if err != nil {
+			return err
+		}
+		// 打印机停止接收时写入也会阻塞，设置截止时间
+		if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			conn.Close()
+			return err
+		}
+		w = conn
+	case "file":
+		if err := os.MkdirAll(filepath.Dir(s.cfg.FilePath), 0755); err != nil {
+			return err
+		}
+		w, err = os.OpenFile(s.cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
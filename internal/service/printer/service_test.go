@@ -0,0 +1,137 @@
+package printer
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sw_call/internal/config"
+	"sw_call/internal/event"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintOverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	cfg := config.Default().Printer
+	cfg.Address = listener.Addr().String()
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+
+	bus := event.NewBus()
+	svc.Subscribe(bus)
+	bus.Publish(event.Event{Type: event.TypeMove, Room: "3诊室", Number: "12", Name: "张三", VisitID: "V20261019001"})
+
+	select {
+	case data := <-received:
+		assert.True(t, bytes.HasPrefix(data, []byte{0x1B, '@'}))
+		// "排队号: 12" 的 GBK 编码
+		assert.Contains(t, string(data), string([]byte{0xC5, 0xC5, 0xB6, 0xD3, 0xBA, 0xC5, ':', ' ', '1', '2'}))
+		assert.Contains(t, string(data), "V20261019001")
+	case <-time.After(2 * time.Second):
+		t.Fatal("未收到打印数据")
+	}
+}
+
+func TestPrintToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "print", "slip.bin")
+
+	cfg := config.Default().Printer
+	cfg.Output = "file"
+	cfg.FilePath = path
+	cfg.QRCode = false
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+
+	slip := Slip{Room: "1诊室", Number: "5", Time: time.Date(2026, 10, 19, 9, 30, 0, 0, time.Local)}
+	assert.NoError(t, svc.Print(slip))
+	assert.NoError(t, svc.Print(slip))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	expected, err := svc.Render(slip)
+	assert.NoError(t, err)
+	assert.Equal(t, append(append([]byte(nil), expected...), expected...), data)
+	assert.NotContains(t, string(data), string([]byte{0x1D, '(', 'k'}))
+}
+
+func TestNewServiceValidation(t *testing.T) {
+	cfg := config.Default().Printer
+	cfg.Output = "usb"
+	_, err := NewService(cfg)
+	assert.Error(t, err)
+
+	cfg = config.Default().Printer
+	cfg.Address = ""
+	_, err = NewService(cfg)
+	assert.Error(t, err)
+}
+
+func TestPrintMoveRequiresNumber(t *testing.T) {
+	cfg := config.Default().Printer
+	cfg.Output = "file"
+	cfg.FilePath = filepath.Join(t.TempDir(), "slip.bin")
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+
+	// 转诊接口未返回新排队号时不打印
+	assert.Error(t, svc.printMove(event.Event{Type: event.TypeMove, Room: "3诊室", Name: "张三"}))
+	_, err = os.Stat(cfg.FilePath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.NoError(t, svc.printMove(event.Event{Type: event.TypeMove, Room: "3诊室", Number: "7"}))
+	assert.FileExists(t, cfg.FilePath)
+}
+
+func TestPrintWriteTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	// 打印机接受连接但不读取数据
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		time.Sleep(5 * time.Second)
+	}()
+
+	cfg := config.Default().Printer
+	cfg.Address = listener.Addr().String()
+	cfg.TimeoutMs = 100
+	svc, err := NewService(cfg)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	err = svc.write(make([]byte, 64<<20))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestTimeoutDefault(t *testing.T) {
+	svc := &Service{cfg: config.PrinterConfig{}}
+	assert.Equal(t, defaultTimeout, svc.timeout())
+	svc.cfg.TimeoutMs = 500
+	assert.Equal(t, 500*time.Millisecond, svc.timeout())
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"fmt"
+
+	"sw_call/internal/config"
+)
+
+// 患者状态，与前端 PATIENT_STATE 保持一致
+const (
+	StateCalling  = 0  // 接诊中
+	StatePriority = 1  // 优先
+	StateWaiting  = 2  // 候诊中
+	StateRevisit  = 3  // 复诊
+	StatePassed   = 4  // 过号
+	StateEnded    = 99 // 结诊
+)
+
+// Patient patient/line/list 返回的排队患者
+type Patient struct {
+	AppointmentID string `json:"appointment_id"`
+	Name          string `json:"name"`
+	Gender        int    `json:"gender"`
+	Age           int    `json:"age"`
+	LineNum       int    `json:"line_num"`
+	State         int    `json:"state"`
+	Disabled      bool   `json:"disabled"`
+}
+
+// Recommendation 推荐结果中的一位患者
+type Recommendation struct {
+	Rank    int     `json:"rank"` // 推荐顺序，从 1 开始；被跳过的患者为 0
+	Patient Patient `json:"patient"`
+	Reason  string  `json:"reason"`
+}
+
+// Result 推荐结果
+type Result struct {
+	Order   []Recommendation `json:"order"`
+	Skipped []Recommendation `json:"skipped"`
+}
+
+// Recommend 按规则对排队列表排序，absent 为本地标记未到的预约ID
+// 同一分组内保持服务端返回的顺序
+func Recommend(patients []Patient, rules config.QueueConfig, absent map[string]bool) Result {
+	var result Result
+	var priority, revisit, normal []Recommendation
+
+	for _, p := range patients {
+		switch p.State {
+		case StateCalling, StatePassed, StateEnded:
+			continue
+		}
+
+		if rules.SkipAbsent && absent[p.AppointmentID] {
+			result.Skipped = append(result.Skipped, Recommendation{Patient: p, Reason: "已标记未到"})
+			continue
+		}
+
+		if reason, ok := priorityReason(p, rules); ok {
+			priority = append(priority, Recommendation{Patient: p, Reason: reason})
+			continue
+		}
+
+		if p.State == StateRevisit && rules.RevisitInterval > 0 {
+			reason := fmt.Sprintf("复诊患者，每 %d 位候诊患者插入一位", rules.RevisitInterval)
+			revisit = append(revisit, Recommendation{Patient: p, Reason: reason})
+			continue
+		}
+
+		reason := "按排队顺序"
+		if p.State == StateRevisit {
+			reason = "复诊患者，按排队顺序"
+		}
+		normal = append(normal, Recommendation{Patient: p, Reason: reason})
+	}
+
+	result.Order = append(result.Order, priority...)
+	result.Order = append(result.Order, interleave(normal, revisit, rules.RevisitInterval)...)
+	for i := range result.Order {
+		result.Order[i].Rank = i + 1
+	}
+	if result.Order == nil {
+		result.Order = []Recommendation{}
+	}
+	return result
+}
+
+// priorityReason 判断患者是否优先就诊
+func priorityReason(p Patient, rules config.QueueConfig) (string, bool) {
+	switch {
+	case p.State == StatePriority:
+		return "优先患者", true
+	case rules.ElderlyPriority && rules.ElderlyAge > 0 && p.Age >= rules.ElderlyAge:
+		return fmt.Sprintf("老年人优先（%d 岁）", p.Age), true
+	case rules.DisabledPriority && p.Disabled:
+		return "残疾人优先", true
+	}
+	return "", false
+}
+
+// interleave 每 n 位候诊患者后插入一位复诊患者
+func interleave(normal, revisit []Recommendation, n int) []Recommendation {
+	if n <= 0 || len(revisit) == 0 {
+		return append(normal, revisit...)
+	}
+
+	order := make([]Recommendation, 0, len(normal)+len(revisit))
+	for i, r := range normal {
+		order = append(order, r)
+		if (i+1)%n == 0 && len(revisit) > 0 {
+			order = append(order, revisit[0])
+			revisit = revisit[1:]
+		}
+	}
+	return append(order, revisit...)
+}
@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"testing"
+
+	"sw_call/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waiting(id string, age int) Patient {
+	return Patient{AppointmentID: id, Age: age, State: StateWaiting}
+}
+
+func revisit(id string) Patient {
+	return Patient{AppointmentID: id, Age: 30, State: StateRevisit}
+}
+
+func ids(recs []Recommendation) []string {
+	result := make([]string, 0, len(recs))
+	for _, r := range recs {
+		result = append(result, r.Patient.AppointmentID)
+	}
+	return result
+}
+
+func TestRecommend(t *testing.T) {
+	defaults := config.Default().Queue
+	noRules := config.QueueConfig{}
+
+	tests := []struct {
+		name     string
+		patients []Patient
+		rules    config.QueueConfig
+		absent   map[string]bool
+		order    []string
+		skipped  []string
+	}{
+		{
+			name:     "无规则时保持服务端顺序",
+			patients: []Patient{waiting("a", 70), revisit("b"), waiting("c", 20)},
+			rules:    noRules,
+			order:    []string{"a", "b", "c"},
+		},
+		{
+			name:     "老年人优先",
+			patients: []Patient{waiting("a", 30), waiting("b", 80), waiting("c", 65)},
+			rules:    defaults,
+			order:    []string{"b", "c", "a"},
+		},
+		{
+			name: "优先状态与残疾人优先",
+			patients: []Patient{
+				waiting("a", 30),
+				{AppointmentID: "b", Age: 40, State: StateWaiting, Disabled: true},
+				{AppointmentID: "c", Age: 20, State: StatePriority},
+			},
+			rules: defaults,
+			order: []string{"b", "c", "a"},
+		},
+		{
+			name: "每 2 位插入一位复诊",
+			patients: []Patient{
+				revisit("r1"), revisit("r2"),
+				waiting("a", 30), waiting("b", 30), waiting("c", 30), waiting("d", 30), waiting("e", 30),
+			},
+			rules: config.QueueConfig{RevisitInterval: 2},
+			order: []string{"a", "b", "r1", "c", "d", "r2", "e"},
+		},
+		{
+			name:     "候诊患者不足时复诊排在最后",
+			patients: []Patient{revisit("r1"), revisit("r2"), waiting("a", 30)},
+			rules:    config.QueueConfig{RevisitInterval: 3},
+			order:    []string{"a", "r1", "r2"},
+		},
+		{
+			name:     "跳过未到患者",
+			patients: []Patient{waiting("a", 30), waiting("b", 30)},
+			rules:    defaults,
+			absent:   map[string]bool{"a": true},
+			order:    []string{"b"},
+			skipped:  []string{"a"},
+		},
+		{
+			name:     "未开启跳过时未到标记无效",
+			patients: []Patient{waiting("a", 30), waiting("b", 30)},
+			rules:    noRules,
+			absent:   map[string]bool{"a": true},
+			order:    []string{"a", "b"},
+		},
+		{
+			name: "排除接诊中、过号和结诊患者",
+			patients: []Patient{
+				{AppointmentID: "a", State: StateCalling},
+				{AppointmentID: "b", State: StatePassed},
+				{AppointmentID: "c", State: StateEnded},
+				waiting("d", 30),
+			},
+			rules: defaults,
+			order: []string{"d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Recommend(tt.patients, tt.rules, tt.absent)
+			assert.Equal(t, tt.order, ids(result.Order))
+			assert.Equal(t, len(tt.skipped), len(result.Skipped))
+			if len(tt.skipped) > 0 {
+				assert.Equal(t, tt.skipped, ids(result.Skipped))
+			}
+
+			for i, r := range result.Order {
+				assert.Equal(t, i+1, r.Rank)
+				assert.NotEmpty(t, r.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"sync"
+
+	"sw_call/internal/config"
+)
+
+// Service 下一位患者推荐服务
+type Service struct {
+	rules config.QueueConfig
+
+	mu     sync.RWMutex
+	absent map[string]bool
+}
+
+// NewService 创建推荐服务
+func NewService(rules config.QueueConfig) *Service {
+	return &Service{
+		rules:  rules,
+		absent: make(map[string]bool),
+	}
+}
+
+// MarkAbsent 标记或取消标记患者未到
+func (s *Service) MarkAbsent(appointmentID string, absent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if absent {
+		s.absent[appointmentID] = true
+		return
+	}
+	delete(s.absent, appointmentID)
+}
+
+// Recommend 对排队列表给出推荐顺序
+func (s *Service) Recommend(patients []Patient) Result {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return Recommend(patients, s.rules, s.absent)
+}
@@ -0,0 +1,111 @@
+package register
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	checkPath  = "/api/v1/s_admin/client_manage/check/%s/1"
+	createPath = "/api/v1/s_admin/client_manage/create"
+
+	// codeNotRegistered 检查接口表示设备未注册的业务码
+	codeNotRegistered = http.StatusNotFound
+)
+
+// Client 客户端管理接口
+type Client struct {
+	http *http.Client
+}
+
+// NewClient 创建客户端管理接口
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		http: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check 检查设备是否已注册
+// 返回 nil 表示设备未注册，其他业务码视为服务端错误，不能据此清除本地绑定
+func (c *Client) Check(ctx context.Context, baseURL, clientID string) (*checkData, error) {
+	endpoint := joinURL(baseURL, fmt.Sprintf(checkPath, url.PathEscape(clientID)))
+
+	var resp apiResponse[*checkData]
+	if err := c.do(ctx, http.MethodGet, endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.Code == codeNotRegistered:
+		return nil, nil
+	case resp.Code != http.StatusOK:
+		return nil, fmt.Errorf("检查设备注册状态失败: code %d %s", resp.Code, firstNonEmpty(resp.Error, resp.Message))
+	case resp.Data == nil:
+		return nil, fmt.Errorf("检查设备注册状态失败: 响应缺少设备信息")
+	}
+	return resp.Data, nil
+}
+
+// Create 提交设备注册信息
+func (c *Client) Create(ctx context.Context, baseURL string, req *createRequest) error {
+	var resp apiResponse[any]
+	if err := c.do(ctx, http.MethodPost, joinURL(baseURL, createPath), req, &resp); err != nil {
+		return err
+	}
+
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("注册设备失败: %s", firstNonEmpty(resp.Error, resp.Message))
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out any) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 失败: %s", endpoint, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// joinURL 拼接服务器地址与接口路径
+func joinURL(baseURL, path string) string {
+	return strings.TrimRight(baseURL, "/") + path
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,327 @@
+package register
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"sw_call/pkg/storage"
+	"sw_call/pkg/system"
+)
+
+const (
+	// BindingKey 机构与诊室绑定缓存在存储中的键
+	BindingKey = "device_binding"
+	// SubmittedKey 注册信息提交记录在存储中的键
+	SubmittedKey = "device_registration"
+
+	forwardURLKey = "forward_url"
+	clientType    = 1 // 呼叫客户端
+
+	// DefaultPollInterval 默认轮询间隔
+	DefaultPollInterval = 30 * time.Second
+	requestTimeout      = 10 * time.Second
+)
+
+// Service 设备注册服务
+type Service struct {
+	store        *storage.DataStore
+	client       *Client
+	clientID     string
+	pollInterval time.Duration
+	refresh      chan struct{}
+
+	mu       sync.RWMutex
+	status   Status
+	onChange func(Status)
+}
+
+// NewService 创建设备注册服务
+func NewService(store *storage.DataStore, clientID string, pollInterval time.Duration) *Service {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &Service{
+		store:        store,
+		client:       NewClient(requestTimeout),
+		clientID:     clientID,
+		pollInterval: pollInterval,
+		refresh:      make(chan struct{}, 1),
+		status: Status{
+			State:     StateUnregistered,
+			ClientID:  clientID,
+			UpdatedAt: time.Now(),
+		},
+	}
+}
+
+// OnChange 设置状态变化回调
+func (s *Service) OnChange(fn func(Status)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Status 返回当前注册状态
+func (s *Service) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// Refresh 立即触发一次注册状态检查
+func (s *Service) Refresh() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// Run 启动注册状态轮询，直到 ctx 结束
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			slog.Warn("检查设备注册状态失败", "client_id", s.clientID, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.refresh:
+		}
+	}
+}
+
+// Sync 与服务端同步一次注册状态
+func (s *Service) Sync(ctx context.Context) error {
+	baseURL := s.forwardURL()
+	if baseURL == "" {
+		s.setStatus(StateUnregistered, nil, "未配置服务器地址", false)
+		return nil
+	}
+
+	data, err := s.client.Check(ctx, baseURL, s.clientID)
+	if err != nil {
+		s.useCache(err)
+		return err
+	}
+
+	if data == nil {
+		return s.handleUnregistered(ctx, baseURL)
+	}
+	s.markSeen()
+
+	status := clientStatusEnabled
+	if data.Status != nil {
+		status = *data.Status
+	}
+
+	switch status {
+	case clientStatusDisabled:
+		// 停用后清除缓存，避免离线启动时绕过停用
+		s.deleteEntry(BindingKey)
+		s.setStatus(StateDisabled, nil, "设备已被停用，请联系管理员", false)
+	case clientStatusPending:
+		s.setStatus(StatePending, nil, "等待管理员审核", false)
+	default:
+		binding := newBinding(data)
+		if len(binding.Rooms) == 0 {
+			s.setStatus(StatePending, binding, "等待管理员分配诊室", false)
+			return nil
+		}
+		if err := s.saveEntry(BindingKey, binding); err != nil {
+			slog.Error("缓存设备绑定信息失败", "error", err)
+		}
+		s.setStatus(StateActive, binding, "", false)
+	}
+
+	return nil
+}
+
+// handleUnregistered 设备未注册时首次提交主机信息，之后等待审核
+// 已绑定或服务端返回过的设备再报告未注册，说明已被管理员删除，清除提交记录后重新提交
+func (s *Service) handleUnregistered(ctx context.Context, baseURL string) error {
+	_, bound := s.loadBinding()
+	s.deleteEntry(BindingKey)
+
+	if sub, ok := s.loadSubmission(); ok {
+		if !bound && !sub.Seen {
+			s.setStatus(StatePending, nil, "等待管理员审核", false)
+			return nil
+		}
+		slog.Warn("设备已从服务端删除，重新提交注册信息", "client_id", s.clientID)
+		s.deleteEntry(SubmittedKey)
+	}
+
+	req, err := s.buildCreateRequest()
+	if err != nil {
+		s.setStatus(StateUnregistered, nil, "获取主机信息失败", false)
+		return err
+	}
+
+	if err := s.client.Create(ctx, baseURL, req); err != nil {
+		s.setStatus(StateUnregistered, nil, err.Error(), false)
+		return err
+	}
+
+	if err := s.saveEntry(SubmittedKey, &submission{SubmittedAt: time.Now()}); err != nil {
+		slog.Error("保存注册提交记录失败", "error", err)
+	}
+	slog.Info("已提交设备注册信息", "client_id", s.clientID, "hostname", req.Hostname)
+	s.setStatus(StatePending, nil, "已提交注册，等待管理员审核", false)
+	return nil
+}
+
+// loadSubmission 读取注册信息提交记录
+func (s *Service) loadSubmission() (*submission, bool) {
+	entry, err := s.store.Load(SubmittedKey)
+	if err != nil || entry.Data == nil {
+		return nil, false
+	}
+
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return nil, false
+	}
+	var sub submission
+	if err := json.Unmarshal(data, &sub); err != nil {
+		return nil, false
+	}
+	return &sub, true
+}
+
+// markSeen 记录服务端已返回过该设备
+func (s *Service) markSeen() {
+	sub, ok := s.loadSubmission()
+	if !ok || sub.Seen {
+		return
+	}
+	sub.Seen = true
+	if err := s.saveEntry(SubmittedKey, sub); err != nil {
+		slog.Error("保存注册提交记录失败", "error", err)
+	}
+}
+
+// useCache 服务器不可达时使用本地缓存的绑定信息
+func (s *Service) useCache(cause error) {
+	binding, ok := s.loadBinding()
+	if !ok {
+		current := s.Status()
+		s.setStatus(current.State, current.Binding, "服务器不可达: "+cause.Error(), true)
+		return
+	}
+	s.setStatus(StateActive, binding, "服务器不可达，使用本地缓存的绑定信息", true)
+}
+
+// setStatus 更新状态并通知回调
+func (s *Service) setStatus(state State, binding *Binding, message string, offline bool) {
+	s.mu.Lock()
+	prev := s.status
+	if !canTransition(prev.State, state) {
+		s.mu.Unlock()
+		slog.Warn("非法的注册状态迁移", "from", prev.State, "to", state)
+		return
+	}
+
+	s.status = Status{
+		State:     state,
+		ClientID:  s.clientID,
+		Binding:   binding,
+		Message:   message,
+		Offline:   offline,
+		UpdatedAt: time.Now(),
+	}
+	next := s.status
+	onChange := s.onChange
+	s.mu.Unlock()
+
+	if prev.State != next.State {
+		slog.Info("设备注册状态变化", "from", prev.State, "to", next.State, "message", message)
+	}
+	if onChange != nil && (prev.State != next.State || prev.Message != next.Message || prev.Offline != next.Offline) {
+		onChange(next)
+	}
+}
+
+// buildCreateRequest 根据本机信息构建注册请求
+func (s *Service) buildCreateRequest() (*createRequest, error) {
+	info, err := system.GetSystemInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	return &createRequest{
+		ClientID:     s.clientID,
+		ClientType:   clientType,
+		Name:         info.Hostname,
+		Hostname:     info.Hostname,
+		OS:           info.OS,
+		Architecture: info.Architecture,
+		CPUInfo:      info.CPUInfo,
+		MemoryTotal:  info.MemoryTotal,
+		IPAddresses:  info.IPAddresses,
+	}, nil
+}
+
+// forwardURL 读取服务器地址
+func (s *Service) forwardURL() string {
+	entry, err := s.store.Load(forwardURLKey)
+	if err != nil || entry.Data == nil {
+		return ""
+	}
+	url, _ := entry.Data.(string)
+	return url
+}
+
+// loadBinding 读取缓存的绑定信息
+func (s *Service) loadBinding() (*Binding, bool) {
+	entry, err := s.store.Load(BindingKey)
+	if err != nil || entry.Data == nil {
+		return nil, false
+	}
+
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return nil, false
+	}
+	var binding Binding
+	if err := json.Unmarshal(data, &binding); err != nil || len(binding.Rooms) == 0 {
+		return nil, false
+	}
+	return &binding, true
+}
+
+func (s *Service) saveEntry(id string, data any) error {
+	return s.store.Save(&storage.DataEntry{
+		ID:   id,
+		Type: "config",
+		Data: data,
+	})
+}
+
+func (s *Service) deleteEntry(id string) {
+	if _, err := s.store.Load(id); err != nil {
+		return
+	}
+	if err := s.store.Delete(id); err != nil {
+		slog.Error("删除本地数据失败", "id", id, "error", err)
+	}
+}
+
+// newBinding 由检查结果构建绑定信息，兼容返回单个 room 的情况
+func newBinding(data *checkData) *Binding {
+	binding := &Binding{
+		Org:   data.Org,
+		Rooms: data.Rooms,
+	}
+	if len(binding.Rooms) == 0 && data.Room != nil {
+		binding.Rooms = []Room{*data.Room}
+	}
+	return binding
+}
@@ -0,0 +1,174 @@
+package register
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"sw_call/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T, baseURL string) *Service {
+	store, err := storage.InitDataStore(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	assert.NoError(t, store.Save(&storage.DataEntry{ID: forwardURLKey, Type: "config", Data: baseURL}))
+	return NewService(store, "client-1", 0)
+}
+
+func TestServiceRegistrationFlow(t *testing.T) {
+	var approved atomic.Bool
+	var created atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/s_admin/client_manage/check/client-1/1":
+			if !approved.Load() {
+				json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "设备未注册"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"code": 200,
+				"data": map[string]any{
+					"org":   map[string]any{"org_id": 1, "org_code": "H001", "org_name": "测试医院"},
+					"rooms": []map[string]any{{"id": 3, "name": "3诊室"}},
+				},
+			})
+		case createPath:
+			var req createRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "client-1", req.ClientID)
+			created.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{"code": 200})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	ctx := context.Background()
+
+	var states []State
+	svc.OnChange(func(s Status) { states = append(states, s.State) })
+
+	// 首次运行提交注册信息
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Equal(t, StatePending, svc.Status().State)
+	assert.False(t, svc.Status().WorkbenchAllowed())
+
+	// 审核前不重复提交
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Equal(t, int32(1), created.Load())
+
+	// 审核通过后激活并缓存绑定
+	approved.Store(true)
+	assert.NoError(t, svc.Sync(ctx))
+	status := svc.Status()
+	assert.Equal(t, StateActive, status.State)
+	assert.Equal(t, "3诊室", status.Binding.Rooms[0].Name)
+
+	binding, ok := svc.loadBinding()
+	assert.True(t, ok)
+	assert.Equal(t, "H001", binding.Org.OrgCode)
+
+	// 服务器不可达时使用缓存
+	server.Close()
+	assert.Error(t, svc.Sync(ctx))
+	assert.Equal(t, StateActive, svc.Status().State)
+	assert.True(t, svc.Status().Offline)
+
+	// 状态或提示信息变化都会通知
+	assert.Equal(t, []State{StatePending, StatePending, StateActive, StateActive}, states)
+}
+
+func TestServiceDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"code": 200,
+			"data": map[string]any{"status": clientStatusDisabled},
+		})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	assert.NoError(t, svc.saveEntry(BindingKey, &Binding{Rooms: []Room{{ID: 1}}}))
+
+	assert.NoError(t, svc.Sync(context.Background()))
+	assert.Equal(t, StateDisabled, svc.Status().State)
+
+	_, ok := svc.loadBinding()
+	assert.False(t, ok)
+}
+
+func TestServiceServerError(t *testing.T) {
+	var code atomic.Int32
+	code.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code.Load() != http.StatusOK {
+			json.NewEncoder(w).Encode(map[string]any{"code": code.Load(), "message": "数据库连接失败"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"code": 200,
+			"data": map[string]any{"rooms": []map[string]any{{"id": 3, "name": "3诊室"}}},
+		})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	assert.NoError(t, svc.Sync(context.Background()))
+	assert.Equal(t, StateActive, svc.Status().State)
+
+	// 服务端业务错误不视为未注册，保留绑定并使用缓存
+	code.Store(http.StatusInternalServerError)
+	assert.ErrorContains(t, svc.Sync(context.Background()), "数据库连接失败")
+	assert.Equal(t, StateActive, svc.Status().State)
+	assert.True(t, svc.Status().Offline)
+	_, ok := svc.loadBinding()
+	assert.True(t, ok)
+}
+
+func TestServiceDeletedResubmits(t *testing.T) {
+	var registered atomic.Bool
+	var created atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == createPath {
+			created.Add(1)
+			registered.Store(true)
+			json.NewEncoder(w).Encode(map[string]any{"code": 200})
+			return
+		}
+		if !registered.Load() {
+			json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "设备未注册"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"code": 200, "data": map[string]any{"status": clientStatusPending}})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	ctx := context.Background()
+	assert.NoError(t, svc.Sync(ctx))
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Equal(t, int32(1), created.Load())
+	assert.Equal(t, StatePending, svc.Status().State)
+
+	// 管理员删除设备后重新提交，而不是一直等待审核
+	registered.Store(false)
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Equal(t, int32(2), created.Load())
+	assert.Equal(t, StatePending, svc.Status().State)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, canTransition(StateUnregistered, StatePending))
+	assert.True(t, canTransition(StateActive, StateActive))
+	assert.False(t, canTransition(StateDisabled, StatePending))
+}
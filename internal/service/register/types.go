@@ -0,0 +1,121 @@
+package register
+
+import "time"
+
+// State 设备注册状态
+type State string
+
+const (
+	// StateUnregistered 未注册
+	StateUnregistered State = "unregistered"
+	// StatePending 已提交注册，等待管理员审核
+	StatePending State = "pending"
+	// StateActive 已激活，可以进入工作台
+	StateActive State = "active"
+	// StateDisabled 已被管理员停用
+	StateDisabled State = "disabled"
+)
+
+// transitions 允许的状态迁移
+var transitions = map[State][]State{
+	StateUnregistered: {StatePending, StateActive, StateDisabled},
+	StatePending:      {StateUnregistered, StateActive, StateDisabled},
+	StateActive:       {StateUnregistered, StatePending, StateDisabled},
+	StateDisabled:     {StateUnregistered, StateActive},
+}
+
+// canTransition 判断状态迁移是否合法
+func canTransition(from, to State) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// 服务端返回的客户端状态
+const (
+	clientStatusPending  = 0 // 待审核
+	clientStatusEnabled  = 1 // 启用
+	clientStatusDisabled = 2 // 停用
+)
+
+// Org 机构信息
+type Org struct {
+	OrgID   int    `json:"org_id"`
+	OrgCode string `json:"org_code"`
+	OrgName string `json:"org_name"`
+	DeptID  int    `json:"dept_id"`
+}
+
+// Room 诊室信息
+type Room struct {
+	ID                      int    `json:"id"`
+	Name                    string `json:"name"`
+	Description             string `json:"description"`
+	DepartmentID            int    `json:"department_id"`
+	RoomType                int    `json:"room_type"`
+	Location                string `json:"location"`
+	ChildProtectionRoomType int    `json:"child_protection_room_type"`
+}
+
+// Binding 管理员分配的机构与诊室绑定
+type Binding struct {
+	Org   Org    `json:"org"`
+	Rooms []Room `json:"rooms"`
+}
+
+// Status 当前注册状态
+type Status struct {
+	State     State     `json:"state"`
+	ClientID  string    `json:"client_id"`
+	Binding   *Binding  `json:"binding,omitempty"`
+	Message   string    `json:"message"`
+	Offline   bool      `json:"offline"` // 是否为服务器不可达时使用的本地缓存
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkbenchAllowed 是否允许进入工作台
+func (s Status) WorkbenchAllowed() bool {
+	return s.State == StateActive
+}
+
+// apiResponse 服务端统一响应结构
+type apiResponse[T any] struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Data    T      `json:"data"`
+}
+
+// checkData 设备检查接口返回数据
+type checkData struct {
+	Status *int   `json:"status"`
+	Org    Org    `json:"org"`
+	Rooms  []Room `json:"rooms"`
+	Room   *Room  `json:"room"`
+}
+
+// submission 注册信息提交记录
+type submission struct {
+	SubmittedAt time.Time `json:"submitted_at"`
+	// Seen 服务端已返回过该设备，之后再报告未注册说明设备被删除，需要重新提交
+	Seen bool `json:"seen"`
+}
+
+// createRequest 设备注册请求
+type createRequest struct {
+	ClientID     string   `json:"client_id"`
+	ClientType   int      `json:"client_type"`
+	Name         string   `json:"name"`
+	Hostname     string   `json:"hostname"`
+	OS           string   `json:"os"`
+	Architecture string   `json:"architecture"`
+	CPUInfo      string   `json:"cpu_info"`
+	MemoryTotal  uint64   `json:"memory_total"`
+	IPAddresses  []string `json:"ip_addresses"`
+}
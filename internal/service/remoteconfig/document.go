@@ -0,0 +1,55 @@
+package remoteconfig
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrInvalidSignature 签名校验失败
+	ErrInvalidSignature = errors.New("invalid config signature")
+	// ErrClientMismatch 配置不属于本机
+	ErrClientMismatch = errors.New("config document belongs to another client")
+)
+
+// Document 服务端下发的配置文档
+type Document struct {
+	ClientID  string `json:"client_id"`
+	Version   int64  `json:"version"`   // 版本号，只接受不低于当前版本的配置
+	Config    string `json:"config"`    // TOML 配置片段
+	Signature string `json:"signature"` // Base64 编码的 Ed25519 签名
+}
+
+// Withdrawn 是否为取消下发的空配置，服务端通过签名的空配置和更高的版本号取消下发
+func (d *Document) Withdrawn() bool {
+	return d.Config == ""
+}
+
+// payload 返回签名内容：client_id、版本号和配置正文以换行连接
+// 签名覆盖 client_id 和版本号，防止配置被挪用到其他机器或回滚
+func (d *Document) payload() []byte {
+	return []byte(d.ClientID + "\n" + strconv.FormatInt(d.Version, 10) + "\n" + d.Config)
+}
+
+// Verify 校验签名和所属客户端
+func (d *Document) Verify(key ed25519.PublicKey, clientID string) error {
+	if d.ClientID != clientID {
+		return fmt.Errorf("%w: %s", ErrClientMismatch, d.ClientID)
+	}
+	sig, err := base64.StdEncoding.DecodeString(d.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(key, d.payload(), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign 使用私钥签名，供配置下发工具和测试使用
+func (d *Document) Sign(key ed25519.PrivateKey) {
+	d.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, d.payload()))
+}
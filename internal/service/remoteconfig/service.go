@@ -0,0 +1,232 @@
+package remoteconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/storage"
+)
+
+const (
+	// CacheKey 最近一次校验通过的远程配置在存储中的键
+	CacheKey = "remote_config"
+
+	forwardURLKey  = "forward_url"
+	configPath     = "/api/v1/s_admin/client_manage/config/%s"
+	requestTimeout = 10 * time.Second
+)
+
+// ApplyFunc 应用远程配置，doc 为 nil 表示服务端已通过签名的空配置取消下发
+// 返回错误时不缓存该配置
+type ApplyFunc func(doc *Document) error
+
+// apiResponse 服务端通用响应
+type apiResponse struct {
+	Code    int       `json:"code"`
+	Data    *Document `json:"data"`
+	Message string    `json:"message"`
+	Error   string    `json:"error"`
+}
+
+// Service 远程配置拉取服务
+type Service struct {
+	store     *storage.DataStore
+	http      *http.Client
+	clientID  string
+	publicKey ed25519.PublicKey
+	interval  time.Duration
+
+	mu      sync.Mutex
+	current *Document
+	apply   ApplyFunc
+}
+
+// NewService 创建远程配置服务，cfg 需已通过校验
+func NewService(store *storage.DataStore, clientID string, cfg config.RemoteConfig) (*Service, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("远程配置公钥无效")
+	}
+	return &Service{
+		store:     store,
+		http:      &http.Client{Timeout: requestTimeout},
+		clientID:  clientID,
+		publicKey: ed25519.PublicKey(key),
+		interval:  time.Duration(cfg.IntervalSec) * time.Second,
+	}, nil
+}
+
+// OnUpdate 设置远程配置变化时的应用回调
+func (s *Service) OnUpdate(fn ApplyFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apply = fn
+}
+
+// Cached 读取并校验本地缓存的远程配置，用于离线启动，已取消下发时返回 nil
+func (s *Service) Cached() *Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.store.Load(CacheKey)
+	if err != nil || entry.Data == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return nil
+	}
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	if err := doc.Verify(s.publicKey, s.clientID); err != nil {
+		slog.Warn("缓存的远程配置校验失败，已忽略", "error", err)
+		return nil
+	}
+
+	s.current = &doc
+	if doc.Withdrawn() {
+		return nil
+	}
+	return &doc
+}
+
+// Run 定期拉取远程配置，直到 ctx 结束
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			slog.Warn("拉取远程配置失败，继续使用当前配置", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 拉取一次远程配置，签名和版本校验通过后应用并缓存
+func (s *Service) Sync(ctx context.Context) error {
+	baseURL := s.forwardURL()
+	if baseURL == "" {
+		return nil
+	}
+
+	doc, err := s.fetch(ctx, baseURL)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 未签名的 404 或空数据可能来自伪造的服务器，不能据此移除已应用的配置
+	if doc == nil {
+		if s.current != nil && !s.current.Withdrawn() {
+			slog.Warn("服务端未返回远程配置，继续使用当前远程配置", "version", s.current.Version)
+		}
+		return nil
+	}
+
+	if err := doc.Verify(s.publicKey, s.clientID); err != nil {
+		return err
+	}
+	if s.current != nil {
+		if doc.Version < s.current.Version {
+			return fmt.Errorf("远程配置版本 %d 低于当前版本 %d，已拒绝", doc.Version, s.current.Version)
+		}
+		if doc.Version == s.current.Version && doc.Config == s.current.Config {
+			return nil
+		}
+		if doc.Withdrawn() && doc.Version == s.current.Version {
+			return fmt.Errorf("取消下发的版本 %d 必须高于当前版本，已拒绝", doc.Version)
+		}
+	}
+
+	// 取消下发时同样缓存空配置，保留版本号防止旧配置被重放
+	if doc.Withdrawn() {
+		if s.current != nil && !s.current.Withdrawn() {
+			if err := s.applyLocked(nil); err != nil {
+				return fmt.Errorf("移除远程配置失败: %w", err)
+			}
+		}
+		slog.Info("服务端已取消下发配置", "version", doc.Version)
+	} else {
+		if err := s.applyLocked(doc); err != nil {
+			return fmt.Errorf("应用远程配置失败: %w", err)
+		}
+		slog.Info("已应用远程配置", "version", doc.Version)
+	}
+	if err := s.store.Save(&storage.DataEntry{ID: CacheKey, Type: "config", Data: doc}); err != nil {
+		slog.Error("缓存远程配置失败", "error", err)
+	}
+	s.current = doc
+	return nil
+}
+
+func (s *Service) applyLocked(doc *Document) error {
+	if s.apply == nil {
+		return nil
+	}
+	return s.apply(doc)
+}
+
+// fetch 请求本机的配置文档，服务端未下发时返回 nil
+func (s *Service) fetch(ctx context.Context, baseURL string) (*Document, error) {
+	endpoint := strings.TrimRight(baseURL, "/") + fmt.Sprintf(configPath, url.PathEscape(s.clientID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求 %s 失败: %s", endpoint, resp.Status)
+	}
+
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	switch body.Code {
+	case http.StatusOK:
+		return body.Data, nil
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	msg := body.Error
+	if msg == "" {
+		msg = body.Message
+	}
+	return nil, fmt.Errorf("获取远程配置失败: %s", msg)
+}
+
+// forwardURL 读取服务器地址
+func (s *Service) forwardURL() string {
+	entry, err := s.store.Load(forwardURLKey)
+	if err != nil || entry.Data == nil {
+		return ""
+	}
+	url, _ := entry.Data.(string)
+	return url
+}
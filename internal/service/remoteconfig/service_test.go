@@ -0,0 +1,141 @@
+package remoteconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"sw_call/internal/config"
+	"sw_call/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T, baseURL string) (*Service, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	store, err := storage.InitDataStore(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	assert.NoError(t, store.Save(&storage.DataEntry{ID: forwardURLKey, Type: "config", Data: baseURL}))
+
+	svc, err := NewService(store, "client-1", config.RemoteConfig{
+		Enabled:     true,
+		PublicKey:   base64.StdEncoding.EncodeToString(pub),
+		IntervalSec: 60,
+	})
+	assert.NoError(t, err)
+	return svc, priv
+}
+
+func TestDocumentVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	doc := &Document{ClientID: "client-1", Version: 3, Config: "[app]\nwidth = 500\n"}
+	doc.Sign(priv)
+	assert.NoError(t, doc.Verify(pub, "client-1"))
+	assert.ErrorIs(t, doc.Verify(pub, "client-2"), ErrClientMismatch)
+
+	// 篡改版本号或正文都会导致校验失败
+	tampered := *doc
+	tampered.Version = 4
+	assert.ErrorIs(t, tampered.Verify(pub, "client-1"), ErrInvalidSignature)
+	tampered = *doc
+	tampered.Config = "[app]\nwidth = 900\n"
+	assert.ErrorIs(t, tampered.Verify(pub, "client-1"), ErrInvalidSignature)
+}
+
+func TestServiceSync(t *testing.T) {
+	var served atomic.Pointer[Document]
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/s_admin/client_manage/config/client-1", r.URL.Path)
+		doc := served.Load()
+		if doc == nil {
+			json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "未配置"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"code": 200, "data": doc})
+	}))
+	defer server.Close()
+
+	svc, priv := newTestService(t, server.URL)
+	ctx := context.Background()
+
+	var applied []*Document
+	var reject bool
+	svc.OnUpdate(func(doc *Document) error {
+		if reject {
+			return errors.New("配置校验失败")
+		}
+		applied = append(applied, doc)
+		return nil
+	})
+
+	// 服务端未下发
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Empty(t, applied)
+
+	v2 := &Document{ClientID: "client-1", Version: 2, Config: "[app]\nwidth = 500\n"}
+	v2.Sign(priv)
+	served.Store(v2)
+	assert.NoError(t, svc.Sync(ctx))
+	assert.NoError(t, svc.Sync(ctx), "相同版本不重复应用")
+	assert.Len(t, applied, 1)
+
+	// 缓存可用于离线启动
+	cached := svc.Cached()
+	assert.Equal(t, v2, cached)
+
+	// 拒绝回滚和错误签名
+	v1 := &Document{ClientID: "client-1", Version: 1, Config: "[app]\nwidth = 400\n"}
+	v1.Sign(priv)
+	served.Store(v1)
+	assert.ErrorContains(t, svc.Sync(ctx), "低于当前版本")
+
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	forged := &Document{ClientID: "client-1", Version: 5, Config: "[app]\nwidth = 400\n"}
+	forged.Sign(otherKey)
+	served.Store(forged)
+	assert.ErrorIs(t, svc.Sync(ctx), ErrInvalidSignature)
+
+	// 应用失败时不缓存
+	v3 := &Document{ClientID: "client-1", Version: 3, Config: "[app]\nwidth = -1\n"}
+	v3.Sign(priv)
+	served.Store(v3)
+	reject = true
+	assert.Error(t, svc.Sync(ctx))
+	assert.Equal(t, int64(2), svc.Cached().Version)
+	reject = false
+
+	// 未签名的 404 不移除当前配置
+	served.Store(nil)
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Len(t, applied, 1)
+	assert.Equal(t, int64(2), svc.Cached().Version)
+
+	// 相同版本的空配置不能取消下发
+	same := &Document{ClientID: "client-1", Version: 2}
+	same.Sign(priv)
+	served.Store(same)
+	assert.ErrorContains(t, svc.Sync(ctx), "必须高于当前版本")
+
+	// 签名的空配置取消下发，之后旧配置不能重放
+	tombstone := &Document{ClientID: "client-1", Version: 4}
+	tombstone.Sign(priv)
+	served.Store(tombstone)
+	assert.NoError(t, svc.Sync(ctx))
+	assert.Len(t, applied, 2)
+	assert.Nil(t, applied[1])
+	assert.Nil(t, svc.Cached())
+	assert.NoError(t, svc.Sync(ctx), "重复的空配置不重复应用")
+	assert.Len(t, applied, 2)
+
+	served.Store(v2)
+	assert.ErrorContains(t, svc.Sync(ctx), "低于当前版本")
+}
@@ -0,0 +1,154 @@
+package weblog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"sw_call/pkg/logger"
+)
+
+const (
+	// DefaultRate 每秒允许写入的前端日志条数
+	DefaultRate = 20
+	// DefaultBurst 短时间内允许的突发条数
+	DefaultBurst = 100
+
+	// MaxMessageLen 消息最大长度（字节），超出部分截断
+	MaxMessageLen = 2000
+	// MaxValueLen 字符串属性最大长度（字节），堆栈信息可能较长
+	MaxValueLen = 8000
+	// MaxAttrs 每条日志最多保留的属性数量
+	MaxAttrs = 20
+)
+
+var (
+	ErrInvalidLevel = errors.New("无效的日志级别")
+	ErrRateLimited  = errors.New("前端日志过多，已限流")
+)
+
+// reservedKeys 前端属性不能覆盖的键
+var reservedKeys = map[string]bool{
+	slog.TimeKey:    true,
+	slog.LevelKey:   true,
+	slog.MessageKey: true,
+	slog.SourceKey:  true,
+}
+
+// Service 将前端日志写入 slog，所有日志带 source=frontend
+type Service struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu         sync.Mutex
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// NewService 创建前端日志服务，rate 为每秒允许的条数，burst 为突发上限
+func NewService(rate, burst int) *Service {
+	return &Service{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+	}
+}
+
+// Log 写入一条前端日志，level 为 debug、info、warn、error
+func (s *Service) Log(ctx context.Context, level, message string, attrs map[string]any) error {
+	lvl, err := logger.ParseLevel(level)
+	if err != nil {
+		return ErrInvalidLevel
+	}
+	// 前端日志最高按 error 记录，fatal 只用于后端
+	lvl = min(lvl, slog.LevelError)
+
+	handler := slog.Default().Handler()
+	if !handler.Enabled(ctx, lvl) {
+		return nil
+	}
+
+	suppressed, ok := s.allow()
+	if suppressed > 0 {
+		r := s.record(slog.LevelWarn, "前端日志过多，部分日志已丢弃")
+		r.AddAttrs(slog.Int("count", suppressed))
+		handler.Handle(ctx, r)
+	}
+	if !ok {
+		return ErrRateLimited
+	}
+
+	r := s.record(lvl, truncate(message, MaxMessageLen))
+	r.AddAttrs(convertAttrs(attrs)...)
+	return handler.Handle(ctx, r)
+}
+
+// allow 按令牌桶限流，返回此前被丢弃且尚未汇报的条数
+func (s *Service) allow() (suppressed int, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !s.last.IsZero() {
+		s.tokens = min(s.burst, s.tokens+now.Sub(s.last).Seconds()*s.rate)
+	}
+	s.last = now
+
+	if s.tokens < 1 {
+		s.suppressed++
+		return 0, false
+	}
+	s.tokens--
+
+	// 恢复写入时汇报丢弃数量
+	suppressed, s.suppressed = s.suppressed, 0
+	return suppressed, true
+}
+
+// record 创建前端日志记录，PC 为 0 时不输出后端的源码位置
+func (s *Service) record(level slog.Level, message string) slog.Record {
+	r := slog.NewRecord(s.now(), level, message, 0)
+	r.AddAttrs(slog.String(slog.SourceKey, "frontend"))
+	return r
+}
+
+// convertAttrs 按键排序转换前端属性，超出数量的属性丢弃
+func convertAttrs(attrs map[string]any) []slog.Attr {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		if key != "" && !reservedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > MaxAttrs {
+		keys = keys[:MaxAttrs]
+	}
+
+	result := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		value := attrs[key]
+		if str, ok := value.(string); ok {
+			value = truncate(str, MaxValueLen)
+		}
+		result = append(result, slog.Any(key, value))
+	}
+	return result
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// 避免截断在多字节字符中间
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,80 @@
+package weblog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	s := NewService(DefaultRate, DefaultBurst)
+	err := s.Log(context.Background(), "error", "获取患者列表失败", map[string]any{
+		"url":    "app.js",
+		"line":   float64(12),
+		"source": "覆盖",
+		"msg":    "覆盖",
+	})
+	assert.NoError(t, err)
+	out := buf.String()
+	assert.Contains(t, out, `level=ERROR msg=获取患者列表失败 source=frontend line=12 url=app.js`)
+	assert.NotContains(t, out, "覆盖")
+
+	// 低于当前等级的日志不写入
+	assert.NoError(t, s.Log(context.Background(), "debug", "调试", nil))
+	assert.NotContains(t, buf.String(), "调试")
+
+	// fatal 按 error 记录
+	assert.NoError(t, s.Log(context.Background(), "fatal", "致命", nil))
+	assert.Contains(t, buf.String(), "level=ERROR msg=致命")
+
+	assert.ErrorIs(t, s.Log(context.Background(), "verbose", "未知", nil), ErrInvalidLevel)
+}
+
+func TestRateLimit(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.Local)
+	s := NewService(2, 3)
+	s.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.Log(ctx, "info", "刷屏", nil))
+	}
+	assert.ErrorIs(t, s.Log(ctx, "info", "刷屏", nil), ErrRateLimited)
+	assert.ErrorIs(t, s.Log(ctx, "info", "刷屏", nil), ErrRateLimited)
+	assert.Equal(t, 3, strings.Count(buf.String(), "刷屏"))
+
+	// 0.5 秒后恢复一个令牌，先汇报丢弃数量
+	now = now.Add(500 * time.Millisecond)
+	assert.NoError(t, s.Log(ctx, "info", "恢复", nil))
+	assert.Contains(t, buf.String(), "msg=前端日志过多，部分日志已丢弃 source=frontend count=2")
+	assert.Contains(t, buf.String(), "msg=恢复")
+	assert.ErrorIs(t, s.Log(ctx, "info", "刷屏", nil), ErrRateLimited)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 5))
+	assert.Equal(t, "ab…", truncate("abcdef", 2))
+	// "患者" 每个字 3 字节，不能截断在字符中间
+	assert.Equal(t, "患…", truncate("患者", 4))
+
+	attrs := make(map[string]any)
+	for i := 0; i < MaxAttrs+5; i++ {
+		attrs[string(rune('a'+i))] = i
+	}
+	assert.Len(t, convertAttrs(attrs), MaxAttrs)
+}
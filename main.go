@@ -2,7 +2,10 @@ package main
 
 import (
 	"embed"
+	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/logger"
@@ -13,23 +16,58 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 
 	"sw_call/internal/config"
+	"sw_call/internal/paths"
+	applog "sw_call/pkg/logger"
 )
 
 //go:embed all:dist
 var assets embed.FS
 
+// defaultConfig 数据目录中没有配置文件时写入的默认配置
+//
+//go:embed root/configs/app.toml
+var defaultConfig []byte
+
 func main() {
-	// 加载配置
-	cfg, err := config.Load("root/configs/app.toml")
+	// 确定数据目录：--data-dir > 便携模式 > 每用户目录
+	dataDir, args := paths.ParseArgs(os.Args[1:], os.Environ())
+	dirs, err := paths.Detect(paths.Options{DataDir: dataDir, Template: defaultConfig})
 	if err != nil {
-		panic(err)
+		showStartupError("呼叫客户端启动失败", "无法确定数据目录:\n\n"+err.Error())
+		os.Exit(1)
 	}
 
+	// 加载配置：默认值 < 配置文件 < 环境变量 < 命令行参数
+	cfgOptions := config.Options{
+		Path:    dirs.ConfigFile(),
+		Environ: os.Environ(),
+		Args:    args,
+		BaseDir: dirs.Base,
+		ExeDir:  dirs.ExeDir,
+	}
+	result, err := config.LoadLayered(cfgOptions)
+	if err != nil {
+		showStartupError("呼叫客户端启动失败", "配置有误，请检查配置文件、环境变量和启动参数:\n\n"+err.Error())
+		os.Exit(1)
+	}
+	if result.PrintConfig {
+		fmt.Printf("# 数据目录: %s (%s)\n", dirs.Base, dirs.Mode)
+		fmt.Print(result.Describe())
+		return
+	}
+	cfg := result.Config
+
 	// 创建应用实例
 	app := NewApp()
 
-	// 将服务注入应用
-	app.Initialize(cfg)
+	// 将服务注入应用，远程配置会在 Initialize 中叠加到 cfg
+	cfgOptions.Path = result.Path
+	app.EnableConfigReload(cfgOptions)
+	if err := app.Initialize(cfg, dirs); err != nil {
+		showStartupError("呼叫客户端启动失败", "无法打开数据存储，请确认没有其他呼叫客户端正在运行:\n\n"+err.Error())
+		os.Exit(1)
+	}
+	result.WarnUnknown()
 
 	err = wails.Run(&options.App{
 		Title:             cfg.App.Title,
@@ -100,6 +138,8 @@ func main() {
 	})
 
 	if err != nil {
-		log.Fatal("应用运行失败: %v", err)
+		// 启动失败时不会调用 shutdown，退出前写完队列中的日志
+		applog.Close(3 * time.Second)
+		log.Fatalf("应用运行失败: %v", err)
 	}
 }
@@ -0,0 +1,188 @@
+package audio
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull 播放队列已满
+var ErrQueueFull = errors.New("audio queue is full")
+
+// Priority 播放优先级，数值越大越先播放
+type Priority int
+
+const (
+	// PriorityNormal 普通提示音
+	PriorityNormal Priority = 0
+	// PriorityUrgent 紧急呼叫提示音
+	PriorityUrgent Priority = 10
+)
+
+// item 队列中的一次播放
+type item struct {
+	name     string
+	clip     *Clip
+	priority Priority
+	seq      uint64
+	done     chan error // 不为 nil 时播放结束后写入结果
+}
+
+// itemHeap 按优先级排序，同优先级先进先出
+type itemHeap []*item
+
+func (h itemHeap) Len() int { return len(h) }
+func (h itemHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h itemHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *itemHeap) Push(x any)   { *h = append(*h, x.(*item)) }
+func (h *itemHeap) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	*h = old[:n-1]
+	return it
+}
+
+// Player 按优先级播放音频片段
+type Player struct {
+	sink     Sink
+	maxQueue int
+	notify   chan struct{}
+	now      func() time.Time
+
+	mu       sync.Mutex
+	queue    itemHeap
+	seq      uint64
+	volume   int
+	schedule Schedule
+
+	// playMu 保证同一时间只有一个片段写入输出
+	playMu sync.Mutex
+}
+
+// NewPlayer 创建播放器
+func NewPlayer(sink Sink, maxQueue int) *Player {
+	if maxQueue <= 0 {
+		maxQueue = 16
+	}
+	return &Player{
+		sink:     sink,
+		maxQueue: maxQueue,
+		notify:   make(chan struct{}, 1),
+		now:      time.Now,
+		volume:   100,
+	}
+}
+
+// SetVolume 设置音量 0-100
+func (p *Player) SetVolume(volume int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = volume
+}
+
+// SetSchedule 设置静音时段
+func (p *Player) SetSchedule(schedule Schedule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.schedule = schedule
+}
+
+// Enqueue 将片段加入播放队列
+func (p *Player) Enqueue(name string, clip *Clip, priority Priority) error {
+	return p.enqueue(&item{name: name, clip: clip, priority: priority})
+}
+
+// EnqueueWait 将片段加入播放队列并等待播放结束，用于语音播报前的提示音
+// ctx 结束时不再等待，片段仍按队列顺序播放
+func (p *Player) EnqueueWait(ctx context.Context, name string, clip *Clip, priority Priority) error {
+	done := make(chan error, 1)
+	if err := p.enqueue(&item{name: name, clip: clip, priority: priority, done: done}); err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Player) enqueue(it *item) error {
+	p.mu.Lock()
+	if len(p.queue) >= p.maxQueue {
+		p.mu.Unlock()
+		return ErrQueueFull
+	}
+	p.seq++
+	it.seq = p.seq
+	heap.Push(&p.queue, it)
+	p.mu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Muted 当前是否处于静音时段
+func (p *Player) Muted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.schedule.Muted(p.now())
+}
+
+// Run 处理播放队列，直到 ctx 结束
+func (p *Player) Run(ctx context.Context) {
+	for {
+		p.mu.Lock()
+		var next *item
+		if len(p.queue) > 0 {
+			next = heap.Pop(&p.queue).(*item)
+		}
+		p.mu.Unlock()
+
+		if next != nil {
+			err := p.play(ctx, next.name, next.clip)
+			if err != nil {
+				slog.Error("播放提示音失败", "name", next.name, "error", err)
+			}
+			if next.done != nil {
+				next.done <- err
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.notify:
+		}
+	}
+}
+
+// play 按音量与静音时段输出片段
+func (p *Player) play(ctx context.Context, name string, clip *Clip) error {
+	p.mu.Lock()
+	volume := p.volume
+	muted := p.schedule.Muted(p.now())
+	p.mu.Unlock()
+
+	if muted || volume <= 0 {
+		slog.Debug("静音中，跳过提示音", "name", name)
+		return nil
+	}
+
+	p.playMu.Lock()
+	defer p.playMu.Unlock()
+	return p.sink.Play(ctx, clip.Scale(volume))
+}
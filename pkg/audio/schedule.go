@@ -0,0 +1,54 @@
+package audio
+
+import (
+	"fmt"
+	"time"
+)
+
+// MuteWindow 静音时段，按一天中的分钟数表示，End 小于 Start 时表示跨越午夜
+type MuteWindow struct {
+	Start int
+	End   int
+}
+
+// Schedule 静音时段表
+type Schedule []MuteWindow
+
+// ParseMuteWindow 解析 "HH:MM" 格式的起止时间
+func ParseMuteWindow(start, end string) (MuteWindow, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return MuteWindow{}, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return MuteWindow{}, err
+	}
+	return MuteWindow{Start: s, End: e}, nil
+}
+
+// Muted 判断给定时间是否处于静音时段
+func (s Schedule) Muted(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range s {
+		if w.Start <= w.End {
+			if minute >= w.Start && minute < w.End {
+				return true
+			}
+			continue
+		}
+		// 跨越午夜
+		if minute >= w.Start || minute < w.End {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,36 @@
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleMuted(t *testing.T) {
+	noon, err := ParseMuteWindow("12:00", "13:30")
+	assert.NoError(t, err)
+	night, err := ParseMuteWindow("22:00", "07:00")
+	assert.NoError(t, err)
+	schedule := Schedule{noon, night}
+
+	tests := []struct {
+		clock string
+		muted bool
+	}{
+		{"11:59", false},
+		{"12:00", true},
+		{"13:29", true},
+		{"13:30", false},
+		{"23:10", true},
+		{"06:59", true},
+		{"07:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clock, func(t *testing.T) {
+			at, _ := time.Parse("15:04", tt.clock)
+			assert.Equal(t, tt.muted, schedule.Muted(at))
+		})
+	}
+}
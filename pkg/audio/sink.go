@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// Sink 音频输出接口
+type Sink interface {
+	// Play 输出一个片段，阻塞直到播放结束
+	Play(ctx context.Context, clip *Clip) error
+}
+
+// FileSink 将每次播放渲染为一个 WAV 文件，用于测试或无声卡的环境
+type FileSink struct {
+	dir string
+
+	mu    sync.Mutex
+	count int
+}
+
+// NewFileSink 创建文件输出
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+// Play 将片段写入文件
+func (f *FileSink) Play(ctx context.Context, clip *Clip) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.count++
+	name := filepath.Join(f.dir, fmt.Sprintf("%06d.wav", f.count))
+	f.mu.Unlock()
+
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return EncodeWAV(file, clip)
+}
+
+// CommandSink 通过外部播放命令输出，WAV 数据写入命令的标准输入
+// 例如 Linux 下的 aplay -q -，各系统的默认命令见 config.Default
+type CommandSink struct {
+	name string
+	args []string
+}
+
+// NewCommandSink 创建命令输出
+func NewCommandSink(name string, args ...string) *CommandSink {
+	return &CommandSink{name: name, args: args}
+}
+
+// Available 检查播放命令是否存在
+func (c *CommandSink) Available() bool {
+	_, err := exec.LookPath(c.name)
+	return err == nil
+}
+
+// Play 调用外部命令播放片段
+func (c *CommandSink) Play(ctx context.Context, clip *Clip) error {
+	var buf bytes.Buffer
+	if err := EncodeWAV(&buf, clip); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	cmd.Stdin = &buf
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("播放音频失败: %w: %s", err, output)
+	}
+	return nil
+}
+
+// NullSink 丢弃所有音频
+type NullSink struct{}
+
+// Play 直接返回
+func (NullSink) Play(ctx context.Context, clip *Clip) error {
+	return ctx.Err()
+}
@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidWAV WAV 文件格式错误
+	ErrInvalidWAV = errors.New("invalid wav data")
+	// ErrUnsupportedFormat 不支持的 WAV 编码格式
+	ErrUnsupportedFormat = errors.New("unsupported wav format: only 16-bit PCM is supported")
+)
+
+// Clip 解码后的 PCM 音频片段
+type Clip struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16 // 多声道时交错存放
+}
+
+// Duration 返回片段时长（毫秒）
+func (c *Clip) Duration() int {
+	if c.SampleRate == 0 || c.Channels == 0 {
+		return 0
+	}
+	return len(c.Samples) * 1000 / (c.SampleRate * c.Channels)
+}
+
+// Scale 返回按音量缩放后的副本，volume 范围 0-100
+func (c *Clip) Scale(volume int) *Clip {
+	if volume < 0 {
+		volume = 0
+	}
+	if volume > 100 {
+		volume = 100
+	}
+
+	scaled := &Clip{
+		SampleRate: c.SampleRate,
+		Channels:   c.Channels,
+		Samples:    make([]int16, len(c.Samples)),
+	}
+	for i, s := range c.Samples {
+		scaled.Samples[i] = int16(int32(s) * int32(volume) / 100)
+	}
+	return scaled
+}
+
+// DecodeWAV 解码 16 位 PCM WAV 数据
+func DecodeWAV(data []byte) (*Clip, error) {
+	r := bytes.NewReader(data)
+
+	var header struct {
+		RIFF [4]byte
+		Size uint32
+		WAVE [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, ErrInvalidWAV
+	}
+	if string(header.RIFF[:]) != "RIFF" || string(header.WAVE[:]) != "WAVE" {
+		return nil, ErrInvalidWAV
+	}
+
+	clip := &Clip{}
+	var gotFormat bool
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, ErrInvalidWAV
+		}
+
+		body := make([]byte, chunk.Size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, ErrInvalidWAV
+		}
+		// 块大小为奇数时有一个填充字节
+		if chunk.Size%2 == 1 {
+			r.ReadByte()
+		}
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, ErrInvalidWAV
+			}
+			format := binary.LittleEndian.Uint16(body[0:2])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			if format != 1 || bits != 16 {
+				return nil, ErrUnsupportedFormat
+			}
+			clip.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			clip.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			gotFormat = true
+		case "data":
+			if !gotFormat {
+				return nil, ErrInvalidWAV
+			}
+			clip.Samples = make([]int16, len(body)/2)
+			for i := range clip.Samples {
+				clip.Samples[i] = int16(binary.LittleEndian.Uint16(body[i*2:]))
+			}
+			return clip, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: missing data chunk", ErrInvalidWAV)
+}
+
+// EncodeWAV 将片段编码为 16 位 PCM WAV
+func EncodeWAV(w io.Writer, clip *Clip) error {
+	dataSize := uint32(len(clip.Samples) * 2)
+	blockAlign := uint16(clip.Channels * 2)
+
+	header := []any{
+		[]byte("RIFF"),
+		36 + dataSize,
+		[]byte("WAVE"),
+		[]byte("fmt "),
+		uint32(16),
+		uint16(1), // PCM
+		uint16(clip.Channels),
+		uint32(clip.SampleRate),
+		uint32(clip.SampleRate) * uint32(blockAlign),
+		blockAlign,
+		uint16(16),
+		[]byte("data"),
+		dataSize,
+	}
+	for _, v := range header {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	return binary.Write(w, binary.LittleEndian, clip.Samples)
+}
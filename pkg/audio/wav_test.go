@@ -0,0 +1,22 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWAVRoundTrip(t *testing.T) {
+	clip := &Clip{SampleRate: 8000, Channels: 1, Samples: []int16{0, 1000, -1000, 32767}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeWAV(&buf, clip))
+
+	decoded, err := DecodeWAV(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, clip, decoded)
+
+	_, err = DecodeWAV([]byte("not a wav"))
+	assert.ErrorIs(t, err, ErrInvalidWAV)
+}
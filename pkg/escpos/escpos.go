@@ -0,0 +1,134 @@
+package escpos
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Align 对齐方式
+type Align byte
+
+const (
+	AlignLeft   Align = 0
+	AlignCenter Align = 1
+	AlignRight  Align = 2
+)
+
+const (
+	esc = 0x1B
+	gs  = 0x1D
+)
+
+// Builder ESC/POS 指令构建器，文本按 GBK 编码
+type Builder struct {
+	buf bytes.Buffer
+	err error
+}
+
+// NewBuilder 创建构建器并初始化打印机
+func NewBuilder() *Builder {
+	b := &Builder{}
+	b.buf.Write([]byte{esc, '@'})
+	// 进入汉字模式
+	b.buf.Write([]byte{0x1C, '&'})
+	return b
+}
+
+// Align 设置对齐方式
+func (b *Builder) Align(align Align) *Builder {
+	b.buf.Write([]byte{esc, 'a', byte(align)})
+	return b
+}
+
+// Bold 设置加粗
+func (b *Builder) Bold(on bool) *Builder {
+	b.buf.Write([]byte{esc, 'E', boolByte(on)})
+	return b
+}
+
+// Size 设置字符放大倍数，宽高范围 1-8
+func (b *Builder) Size(width, height int) *Builder {
+	width, height = clamp(width, 1, 8), clamp(height, 1, 8)
+	b.buf.Write([]byte{gs, '!', byte((width-1)<<4 | (height - 1))})
+	return b
+}
+
+// Text 输出文本
+func (b *Builder) Text(text string) *Builder {
+	data, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+	if err != nil && b.err == nil {
+		b.err = fmt.Errorf("文本无法编码为 GBK: %w", err)
+		return b
+	}
+	b.buf.Write(data)
+	return b
+}
+
+// Line 输出文本并换行
+func (b *Builder) Line(text string) *Builder {
+	return b.Text(text).Feed(1)
+}
+
+// Feed 走纸 n 行
+func (b *Builder) Feed(lines int) *Builder {
+	b.buf.Write([]byte{esc, 'd', byte(clamp(lines, 0, 255))})
+	return b
+}
+
+// QRCode 打印二维码，size 为模块大小 1-16
+func (b *Builder) QRCode(data string, size int) *Builder {
+	payload := []byte(data)
+	if len(payload)+3 > 0xFFFF {
+		if b.err == nil {
+			b.err = fmt.Errorf("二维码内容过长: %d 字节", len(payload))
+		}
+		return b
+	}
+
+	// 选择模型 2
+	b.buf.Write([]byte{gs, '(', 'k', 4, 0, '1', 'A', '2', 0})
+	// 模块大小
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'C', byte(clamp(size, 1, 16))})
+	// 纠错等级 M
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'E', '1'})
+	// 存储数据
+	n := len(payload) + 3
+	b.buf.Write([]byte{gs, '(', 'k', byte(n), byte(n >> 8), '1', 'P', '0'})
+	b.buf.Write(payload)
+	// 打印
+	b.buf.Write([]byte{gs, '(', 'k', 3, 0, '1', 'Q', '0'})
+	return b
+}
+
+// Cut 走纸并切纸
+func (b *Builder) Cut() *Builder {
+	b.buf.Write([]byte{gs, 'V', 66, 0})
+	return b
+}
+
+// Bytes 返回指令字节流
+func (b *Builder) Bytes() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.buf.Bytes(), nil
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+package escpos
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	data, err := NewBuilder().
+		Align(AlignCenter).
+		Bold(true).
+		Size(2, 2).
+		Line("诊室").
+		QRCode("V001", 6).
+		Cut().
+		Bytes()
+	assert.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(data, []byte{0x1B, '@'}))
+	assert.Contains(t, string(data), string([]byte{0x1B, 'a', 1}))
+	assert.Contains(t, string(data), string([]byte{0x1D, '!', 0x11}))
+	// "诊室" 的 GBK 编码
+	assert.Contains(t, string(data), string([]byte{0xD5, 0xEF, 0xCA, 0xD2}))
+	// 二维码数据长度为内容长度加 3
+	assert.Contains(t, string(data), string([]byte{0x1D, '(', 'k', 7, 0, '1', 'P', '0', 'V', '0', '0', '1'}))
+	assert.True(t, bytes.HasSuffix(data, []byte{0x1D, 'V', 66, 0}))
+}
+
+func TestBuilderEncodeError(t *testing.T) {
+	_, err := NewBuilder().Text("😀").Bytes()
+	assert.Error(t, err)
+}
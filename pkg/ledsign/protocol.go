@@ -0,0 +1,137 @@
+package ledsign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Checksum 校验方式
+type Checksum string
+
+const (
+	ChecksumNone  Checksum = "none"  // 不校验
+	ChecksumSum8  Checksum = "sum8"  // 字节累加和取低 8 位
+	ChecksumXOR   Checksum = "xor"   // 字节异或
+	ChecksumCRC16 Checksum = "crc16" // CRC-16/MODBUS，低字节在前
+)
+
+// Protocol 串口 LED 屏帧格式
+// 帧结构：帧头 | 地址(可选) | 长度(可选) | 内容 | 校验(可选) | 帧尾
+// 校验范围为帧头之后、校验之前的所有字节
+type Protocol struct {
+	Header      []byte
+	Footer      []byte
+	Address     int // 屏地址，小于 0 时不发送
+	LengthBytes int // 长度字段字节数 0/1/2，大端
+	Checksum    Checksum
+	Encoding    string // gb2312 或 utf8
+}
+
+// ParseHex 解析十六进制字符串，允许空格分隔，例如 "AA 55"
+func ParseHex(value string) ([]byte, error) {
+	return hex.DecodeString(strings.ReplaceAll(value, " ", ""))
+}
+
+// Encode 将文本编码为一帧数据
+func (p Protocol) Encode(text string) ([]byte, error) {
+	payload, err := p.encodeText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if p.Address >= 0 {
+		if p.Address > 0xFF {
+			return nil, fmt.Errorf("屏地址超出范围: %d", p.Address)
+		}
+		body = append(body, byte(p.Address))
+	}
+
+	switch p.LengthBytes {
+	case 0:
+	case 1:
+		if len(payload) > 0xFF {
+			return nil, fmt.Errorf("内容过长: %d 字节", len(payload))
+		}
+		body = append(body, byte(len(payload)))
+	case 2:
+		if len(payload) > 0xFFFF {
+			return nil, fmt.Errorf("内容过长: %d 字节", len(payload))
+		}
+		body = append(body, byte(len(payload)>>8), byte(len(payload)))
+	default:
+		return nil, fmt.Errorf("不支持的长度字段字节数: %d", p.LengthBytes)
+	}
+	body = append(body, payload...)
+
+	sum, err := checksum(p.Checksum, body)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, 0, len(p.Header)+len(body)+len(sum)+len(p.Footer))
+	frame = append(frame, p.Header...)
+	frame = append(frame, body...)
+	frame = append(frame, sum...)
+	frame = append(frame, p.Footer...)
+	return frame, nil
+}
+
+// encodeText 按配置的字符集编码文本
+func (p Protocol) encodeText(text string) ([]byte, error) {
+	switch strings.ToLower(p.Encoding) {
+	case "", "gb2312", "gbk":
+		// GBK 兼容 GB2312，GB2312 字符的编码结果相同
+		data, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("文本无法编码为 GB2312: %w", err)
+		}
+		return data, nil
+	case "utf8", "utf-8":
+		return []byte(text), nil
+	}
+	return nil, fmt.Errorf("不支持的编码: %s", p.Encoding)
+}
+
+// checksum 计算校验值
+func checksum(kind Checksum, data []byte) ([]byte, error) {
+	switch kind {
+	case "", ChecksumNone:
+		return nil, nil
+	case ChecksumSum8:
+		var sum byte
+		for _, b := range data {
+			sum += b
+		}
+		return []byte{sum}, nil
+	case ChecksumXOR:
+		var sum byte
+		for _, b := range data {
+			sum ^= b
+		}
+		return []byte{sum}, nil
+	case ChecksumCRC16:
+		crc := crc16Modbus(data)
+		return []byte{byte(crc), byte(crc >> 8)}, nil
+	}
+	return nil, fmt.Errorf("不支持的校验方式: %s", kind)
+}
+
+// crc16Modbus 计算 CRC-16/MODBUS
+func crc16Modbus(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,66 @@
+package ledsign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocolEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol Protocol
+		text     string
+		want     []byte
+	}{
+		{
+			name:     "GB2312 无校验",
+			protocol: Protocol{Address: -1, Checksum: ChecksumNone},
+			text:     "张三",
+			want:     []byte{0xD5, 0xC5, 0xC8, 0xFD},
+		},
+		{
+			name:     "帧头帧尾与累加和",
+			protocol: Protocol{Header: []byte{0xAA}, Footer: []byte{0x55}, Address: 1, LengthBytes: 1, Checksum: ChecksumSum8, Encoding: "utf8"},
+			text:     "12",
+			want:     []byte{0xAA, 0x01, 0x02, '1', '2', 0x01 + 0x02 + '1' + '2', 0x55},
+		},
+		{
+			name:     "异或校验与两字节长度",
+			protocol: Protocol{Address: -1, LengthBytes: 2, Checksum: ChecksumXOR, Encoding: "utf8"},
+			text:     "A",
+			want:     []byte{0x00, 0x01, 'A', 0x00 ^ 0x01 ^ 'A'},
+		},
+		{
+			name:     "CRC16 MODBUS",
+			protocol: Protocol{Address: -1, Checksum: ChecksumCRC16, Encoding: "utf8"},
+			text:     "123456789",
+			want:     append([]byte("123456789"), 0x37, 0x4B),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := tt.protocol.Encode(tt.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, frame)
+		})
+	}
+}
+
+func TestProtocolEncodeErrors(t *testing.T) {
+	_, err := Protocol{Address: -1, Checksum: "md5"}.Encode("x")
+	assert.Error(t, err)
+
+	_, err = Protocol{Address: -1, Encoding: "big5"}.Encode("x")
+	assert.Error(t, err)
+
+	_, err = Protocol{Address: 300}.Encode("x")
+	assert.Error(t, err)
+}
+
+func TestParseHex(t *testing.T) {
+	data, err := ParseHex("AA 55 0d")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xAA, 0x55, 0x0D}, data)
+}
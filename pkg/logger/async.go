@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 队列满时的处理策略
+const (
+	OverflowBlock = "block" // 阻塞调用方直到队列有空位
+	OverflowDrop  = "drop"  // 丢弃日志并计数，error 及以上级别仍然阻塞写入
+)
+
+// Overflows 合法的队列满处理策略
+var Overflows = []string{OverflowBlock, OverflowDrop}
+
+// ErrFlushTimeout 等待日志写完超时
+var ErrFlushTimeout = errors.New("等待日志写入超时")
+
+// droppedReportInterval 汇报丢弃数量的间隔
+var droppedReportInterval = time.Minute
+
+// dispatcher 当前的异步分发器，为 nil 表示同步写入
+var dispatcher atomic.Pointer[asyncDispatcher]
+
+// entry 队列中的一条日志，ack 不为 nil 时表示 Flush 标记
+type entry struct {
+	handler *MultiLevelHandler
+	ctx     context.Context
+	record  slog.Record
+	ack     chan struct{}
+}
+
+// asyncDispatcher 在单独的 goroutine 中写日志，调用方只负责入队
+type asyncDispatcher struct {
+	queue    chan entry
+	drop     bool
+	dropped  [4]atomic.Uint64 // 按 debug、info、warn、error 计数
+	reported [4]uint64
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+
+	// mu 保证关闭后不再入队，关闭前入队的日志都会被分发 goroutine 写完
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncDispatcher(size int, overflow string) *asyncDispatcher {
+	d := &asyncDispatcher{
+		queue: make(chan entry, size),
+		drop:  overflow == OverflowDrop,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// enqueue 将日志放入队列，返回 false 表示已丢弃
+func (d *asyncDispatcher) enqueue(e entry) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		// 分发器已关闭，直接写入
+		e.handler.write(e.ctx, e.record)
+		return true
+	}
+
+	// 持有读锁期间分发 goroutine 不会退出，阻塞的入队最终都会被取走
+	if !d.drop || e.record.Level >= slog.LevelError {
+		d.queue <- e
+		return true
+	}
+
+	select {
+	case d.queue <- e:
+		return true
+	default:
+		d.dropped[levelIndex(e.record.Level)].Add(1)
+		return false
+	}
+}
+
+func (d *asyncDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(droppedReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e := <-d.queue:
+			d.handle(e)
+		case <-ticker.C:
+			d.reportDropped()
+		case <-d.stop:
+			// 写完队列中剩余的日志再退出
+			for {
+				select {
+				case e := <-d.queue:
+					d.handle(e)
+				default:
+					d.reportDropped()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *asyncDispatcher) handle(e entry) {
+	if e.ack != nil {
+		close(e.ack)
+		return
+	}
+	e.handler.write(e.ctx, e.record)
+}
+
+// reportDropped 记录上次汇报以来丢弃的日志数量，在分发 goroutine 中直接写入，不经过队列
+func (d *asyncDispatcher) reportDropped() {
+	var attrs []slog.Attr
+	for i, name := range []string{"debug", "info", "warn", "error"} {
+		total := d.dropped[i].Load()
+		if n := total - d.reported[i]; n > 0 {
+			attrs = append(attrs, slog.Uint64(name, n))
+		}
+		d.reported[i] = total
+	}
+	if len(attrs) == 0 {
+		return
+	}
+
+	h, ok := slog.Default().Handler().(*MultiLevelHandler)
+	if !ok {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "日志队列已满，部分日志已丢弃", 0)
+	r.AddAttrs(attrs...)
+	h.write(context.Background(), r)
+}
+
+// flush 等待此前入队的日志全部写完
+func (d *asyncDispatcher) flush(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ack := make(chan struct{})
+	select {
+	case d.queue <- entry{ack: ack}:
+	case <-d.done:
+		return nil
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-d.done:
+		return nil
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
+}
+
+// close 停止分发 goroutine，剩余的日志写完后返回
+func (d *asyncDispatcher) close(timeout time.Duration) error {
+	// 等待进行中的入队完成后再通知退出，写入卡住时加锁也会等待，放到后台以保证超时返回
+	go d.once.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		d.mu.Unlock()
+		close(d.stop)
+	})
+	select {
+	case <-d.done:
+		return nil
+	case <-time.After(timeout):
+		return ErrFlushTimeout
+	}
+}
+
+func levelIndex(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 0
+	case level < slog.LevelWarn:
+		return 1
+	case level < slog.LevelError:
+		return 2
+	}
+	return 3
+}
+
+// Dropped 返回启动以来因队列已满丢弃的日志总数
+func Dropped() uint64 {
+	d := dispatcher.Load()
+	if d == nil {
+		return 0
+	}
+	var total uint64
+	for i := range d.dropped {
+		total += d.dropped[i].Load()
+	}
+	return total
+}
+
+// Flush 等待已记录的日志全部写入，同步模式下直接返回
+func Flush(timeout time.Duration) error {
+	d := dispatcher.Load()
+	if d == nil {
+		return nil
+	}
+	return d.flush(timeout)
+}
+
+// files 当前的日志文件，为 nil 表示未启用文件输出
+var files atomic.Pointer[rotateSet]
+
+// Close 写完剩余日志并停止异步写入，等待后台清理结束后关闭日志文件，应用退出前调用
+// 之后的日志同步写入，文件在写入时重新打开
+func Close(timeout time.Duration) error {
+	var err error
+	if d := dispatcher.Swap(nil); d != nil {
+		err = d.close(timeout)
+	}
+	if s := files.Load(); s != nil {
+		err = errors.Join(err, s.close(timeout))
+	}
+	return err
+}
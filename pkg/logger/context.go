@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// 常用的请求属性键
+const (
+	KeyClientID = "client_id"
+	KeyDocID    = "doc_id"
+	KeyTraceID  = "trace_id"
+)
+
+type ctxKey struct{}
+
+// WithAttrs 返回携带日志属性的 context，使用 slog 的 *Context 方法记录时自动附加到每条日志
+// 同名属性以后加入的为准
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	prev := AttrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	for _, a := range prev {
+		if !containsKey(attrs, a.Key) {
+			merged = append(merged, a)
+		}
+	}
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// AttrsFromContext 返回 context 中携带的日志属性
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// WithClientID 附加客户端ID
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return WithAttrs(ctx, slog.String(KeyClientID, clientID))
+}
+
+// WithDocID 附加医生ID
+func WithDocID(ctx context.Context, docID int) context.Context {
+	return WithAttrs(ctx, slog.Int(KeyDocID, docID))
+}
+
+// WithTraceID 附加链路ID，traceID 为空时生成新的
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		traceID = NewTraceID()
+	}
+	return WithAttrs(ctx, slog.String(KeyTraceID, traceID))
+}
+
+// NewTraceID 生成 16 位十六进制链路ID
+func NewTraceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func containsKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
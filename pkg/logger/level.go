@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelFatal fatal 级别，slog 没有内置
+const LevelFatal = slog.Level(12)
+
+// levelVar 当前日志等级，所有输出共用，运行时可通过 SetLevel 修改
+var levelVar = &slog.LevelVar{}
+
+// levelState 临时等级和变化回调
+var levelState struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	revertAt time.Time
+	onChange []func(slog.Level)
+}
+
+// atLeast 返回不低于 floor 且跟随 levelVar 的等级
+type atLeast slog.Level
+
+func (l atLeast) Level() slog.Level {
+	return max(slog.Level(l), levelVar.Level())
+}
+
+// ParseLevel 解析日志级别名称: debug, info, warn, error, fatal
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	}
+	return 0, fmt.Errorf("未知的日志级别: %q", name)
+}
+
+// LevelName 返回等级名称，与 ParseLevel 对应
+func LevelName(level slog.Level) string {
+	if level >= LevelFatal {
+		return "fatal"
+	}
+	return strings.ToLower(level.String())
+}
+
+// SetLevel 运行时修改日志等级，取消尚未到期的自动恢复
+func SetLevel(level slog.Level) {
+	levelState.mu.Lock()
+	stopRevert()
+	levelState.mu.Unlock()
+	setLevel(level)
+}
+
+// SetLevelFor 临时修改日志等级，d 之后自动恢复为 fallback
+func SetLevelFor(level slog.Level, d time.Duration, fallback slog.Level) {
+	levelState.mu.Lock()
+	stopRevert()
+	levelState.revertAt = time.Now().Add(d)
+	levelState.timer = time.AfterFunc(d, func() {
+		levelState.mu.Lock()
+		levelState.timer = nil
+		levelState.revertAt = time.Time{}
+		levelState.mu.Unlock()
+
+		slog.Info("临时日志等级已到期，恢复默认等级", "level", LevelName(fallback))
+		setLevel(fallback)
+	})
+	levelState.mu.Unlock()
+	setLevel(level)
+}
+
+// GetLevel 返回当前日志等级
+func GetLevel() slog.Level {
+	return levelVar.Level()
+}
+
+// RevertAt 返回临时等级的恢复时间，没有临时等级时为零值
+func RevertAt() time.Time {
+	levelState.mu.Lock()
+	defer levelState.mu.Unlock()
+	return levelState.revertAt
+}
+
+// OnLevelChange 注册日志等级变化回调
+func OnLevelChange(fn func(slog.Level)) {
+	levelState.mu.Lock()
+	defer levelState.mu.Unlock()
+	levelState.onChange = append(levelState.onChange, fn)
+}
+
+func setLevel(level slog.Level) {
+	prev := levelVar.Level()
+	levelVar.Set(level)
+	if prev == level {
+		return
+	}
+
+	levelState.mu.Lock()
+	callbacks := levelState.onChange
+	levelState.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(level)
+	}
+}
+
+// stopRevert 取消自动恢复，调用方需持有 levelState.mu
+func stopRevert() {
+	if levelState.timer != nil {
+		levelState.timer.Stop()
+		levelState.timer = nil
+	}
+	levelState.revertAt = time.Time{}
+}
@@ -2,113 +2,84 @@ package logger
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sw_call/pkg/logger/devslog"
-
-	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+	"time"
 )
 
-// 保存一个日志队列，使用 channel 实现
-var logQueue = make(chan slog.Record, 1000)
-
 type Config struct {
 	Path       string
-	MaxSize    int    //文件大小限制,单位MB
-	MaxAge     int    //日志文件保留天数
-	MaxBackups int    //最大保留日志文件数量
-	Compress   bool   //是否压缩处理
-	Level      int    // 等级
-	Mode       string // 日志输出模式 debug release
-}
+	MaxSize    int        //文件大小限制,单位MB
+	MaxAge     int        //日志文件保留天数
+	MaxBackups int        //最大保留日志文件数量
+	MaxTotal   int        // 所有日志文件的总大小上限，单位MB，0 表示不限
+	Daily      bool       // 每天轮转
+	Compress   bool       //是否压缩处理
+	Level      slog.Level // 等级
+	Output     string     // 输出方式 stdout file both none
+	QueueSize  int        // 异步队列长度，0 表示同步写入
+	Overflow   string     // 队列满时的处理策略 block drop
+	Redactor   *Redactor  // 日志脱敏，为 nil 表示不脱敏
 
-var LogLevel = map[int]slog.Level{
-	-1: slog.LevelDebug,
-	0:  slog.LevelInfo,
-	1:  slog.LevelWarn,
-	2:  slog.LevelError,
+	Now func() time.Time // 轮转使用的时钟，为 nil 时使用 time.Now，测试时替换
 }
 
-func NewLoggerWrapper(cfg Config) {
-	NewMultiLevelHandler(cfg)
-}
+// 日志输出方式
+const (
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+	OutputBoth   = "both"
+	OutputNone   = "none"
+)
+
+// Outputs 合法的输出方式
+var Outputs = []string{OutputStdout, OutputFile, OutputBoth, OutputNone}
 
-type LimeWriter struct {
-	mode       string
-	lumberjack *lumberjack.Logger
+func (c Config) console() bool {
+	return c.Output == OutputStdout || c.Output == OutputBoth
 }
 
-func (l *LimeWriter) Write(p []byte) (n int, err error) {
-	if l.mode == "debug" {
-		os.Stdout.Write(p)
-	}
+func (c Config) file() bool {
+	return c.Output == OutputFile || c.Output == OutputBoth
+}
 
-	return l.lumberjack.Write(p)
+func NewLoggerWrapper(cfg Config) {
+	NewMultiLevelHandler(cfg)
 }
 
 // MultiLevelHandler 自定义 Handler 处理多个日志级别输出
+// 各输出为 nil 表示未启用，WithAttrs、WithGroup 派生的处理器保留全部输出
 type MultiLevelHandler struct {
-	debugHandler        *slog.JSONHandler
-	infoHandler         *slog.JSONHandler
-	errorHandler        *slog.JSONHandler
-	consoleHandler      *slog.TextHandler
-	consoleColorHandler *devslog.DevelopHandler
-	handlerOptions      *slog.HandlerOptions
-	level               slog.Level
+	debugHandler        slog.Handler
+	infoHandler         slog.Handler
+	errorHandler        slog.Handler
+	consoleColorHandler slog.Handler
+	redactor            *Redactor
+
+	// 进入分组前的处理器和之后的派生操作，context 属性加在分组外层
+	ungrouped *MultiLevelHandler
+	grouped   []func(slog.Handler) slog.Handler
 }
 
 func (h *MultiLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= levelVar.Level()
 }
 
 // NewMultiLevelHandler 初始化日志处理器
 func NewMultiLevelHandler(cfg Config) {
-	infoPath := filepath.Join(cfg.Path, "info.log")
-	debugPath := filepath.Join(cfg.Path, "debug.log")
-	errorPath := filepath.Join(cfg.Path, "error.log")
-
-	// 打开日志文件
-	//debugFile, _ := os.OpenFile(debugPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	//infoFile, _ := os.OpenFile(infoPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	//errorFile, _ := os.OpenFile(errorPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-
-	levelVar := &slog.LevelVar{}
-	levelVar.Set(LogLevel[cfg.Level])
-	fmt.Println("当前日志等级:", LogLevel[cfg.Level])
-
-	infoWriter := &lumberjack.Logger{
-		Filename:   infoPath,       // 日志文件路径
-		MaxSize:    cfg.MaxSize,    // 单个文件最大大小（MB）
-		MaxBackups: cfg.MaxBackups, // 保留旧文件的最大数量
-		MaxAge:     cfg.MaxAge,     // 保留旧文件的最大天数
-		Compress:   cfg.Compress,   // 是否压缩/归档旧文件
-	}
-	debugWriter := &lumberjack.Logger{
-		Filename:   debugPath,      // 日志文件路径
-		MaxSize:    cfg.MaxSize,    // 单个文件最大大小（MB）
-		MaxBackups: cfg.MaxBackups, // 保留旧文件的最大数量
-		MaxAge:     cfg.MaxAge,     // 保留旧文件的最大天数
-		Compress:   cfg.Compress,   // 是否压缩/归档旧文件
-	}
-	errorWriter := &lumberjack.Logger{
-		Filename:   errorPath,      // 日志文件路径
-		MaxSize:    cfg.MaxSize,    // 单个文件最大大小（MB）
-		MaxBackups: cfg.MaxBackups, // 保留旧文件的最大数量
-		MaxAge:     cfg.MaxAge,     // 保留旧文件的最大天数
-		Compress:   cfg.Compress,   // 是否压缩/归档旧文件
-	}
-
-	handlerOptions := func(levelVar slog.Level) *slog.HandlerOptions {
+	levelVar.Set(cfg.Level)
+
+	handlerOptions := func(levelVar slog.Leveler) *slog.HandlerOptions {
 		return &slog.HandlerOptions{
 			Level:     levelVar,
 			AddSource: true,
 			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-				if a.Key == slog.SourceKey {
-					source := a.Value.Any().(*slog.Source)
+				// 前端日志的 source 为字符串，不做处理
+				if source, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey {
 					// 只要最后两个路径
 					paths := strings.Split(source.File, "/")
 					if len(paths) > 2 {
@@ -123,18 +94,19 @@ func NewMultiLevelHandler(cfg Config) {
 					// return slog.String(a.Key, a.Value.Time().Format("2006-01-02 15:04:05.999"))
 					return slog.String(a.Key, a.Value.String())
 				}
+				// 脱敏，所有输出共用同一套规则
+				if cfg.Redactor != nil {
+					return cfg.Redactor.ReplaceAttr(groups, a)
+				}
 
 				return a
 			},
 		}
 	}
 
-	fmt.Println("debug日志初始化成功 path:", debugPath)
-	fmt.Println("info日志初始化成功 path:", infoPath)
-	fmt.Println("error日志初始化成功 path:", errorPath)
+	handler := &MultiLevelHandler{redactor: cfg.Redactor}
 
-	var consoleColorHandler *devslog.DevelopHandler
-	if LogLevel[cfg.Level] == slog.LevelDebug {
+	if cfg.console() {
 		// new logger with options
 		opts := &devslog.Options{
 			MaxSlicePrintSize: 4,
@@ -143,43 +115,88 @@ func NewMultiLevelHandler(cfg Config) {
 			NewLineAfterLog:   true,
 			//DebugColor:        devslog.Magenta,
 			StringerFormatter: true,
-			HandlerOptions:    handlerOptions(levelVar.Level()),
+			HandlerOptions:    handlerOptions(levelVar),
 		}
-		consoleColorHandler = devslog.NewHandler(os.Stdout, opts)
+		handler.consoleColorHandler = devslog.NewHandler(os.Stdout, opts)
 	}
 
-	handler := &MultiLevelHandler{
-		debugHandler:        slog.NewJSONHandler(debugWriter, handlerOptions(slog.LevelDebug)),
-		infoHandler:         slog.NewJSONHandler(infoWriter, handlerOptions(slog.LevelInfo)),
-		errorHandler:        slog.NewJSONHandler(errorWriter, handlerOptions(slog.LevelError)),
-		consoleColorHandler: consoleColorHandler,
-		level:               levelVar.Level(),
+	var set *rotateSet
+	if cfg.file() {
+		// 三个文件共享清理规则和磁盘空间上限
+		set = newRotateSet(cfg)
+		handler.debugHandler = slog.NewJSONHandler(set.writer("debug"), handlerOptions(levelVar))
+		handler.infoHandler = slog.NewJSONHandler(set.writer("info"), handlerOptions(atLeast(slog.LevelInfo)))
+		handler.errorHandler = slog.NewJSONHandler(set.writer("error"), handlerOptions(atLeast(slog.LevelError)))
+		// 长时间没有轮转时，启动时清理上次运行留下的过期文件
+		set.startMill()
 	}
 
 	slog.SetDefault(slog.New(handler))
+
+	// 重新初始化时先写完旧队列中的日志
+	var async *asyncDispatcher
+	if cfg.QueueSize > 0 {
+		async = newAsyncDispatcher(cfg.QueueSize, cfg.Overflow)
+	}
+	if old := dispatcher.Swap(async); old != nil {
+		old.close(time.Second)
+	}
+	// 旧队列写完后再关闭旧文件
+	if old := files.Swap(set); old != nil {
+		old.close(time.Second)
+	}
 }
 
-// Handle 实现 slog.Handler 接口，根据日志级别写入不同的文件
+// Handle 实现 slog.Handler 接口，启用异步队列时只负责入队
 func (h *MultiLevelHandler) Handle(ctx context.Context, r slog.Record) error {
-	// debug模式就显示控制台日志，否则不输出日志
-	if h.level == slog.LevelDebug {
-		if err := h.consoleColorHandler.Handle(ctx, r); err != nil {
-			return err
+	// 注入 context 中的请求属性，始终作为顶层字段
+	if attrs := AttrsFromContext(ctx); len(attrs) > 0 {
+		if h.ungrouped != nil {
+			h = h.withTopLevel(attrs)
+		} else {
+			r = r.Clone()
+			r.AddAttrs(attrs...)
 		}
 	}
-	// 所有日志都写入 debug.log
-	if err := h.debugHandler.Handle(ctx, r); err != nil {
-		return err
+	// 属性在各输出的 ReplaceAttr 中脱敏，消息在这里统一处理
+	if h.redactor != nil {
+		r.Message = h.redactor.String(r.Message)
 	}
-	// info 级别及以上日志写入 info.log
-	if r.Level >= slog.LevelInfo {
-		if err := h.infoHandler.Handle(ctx, r); err != nil {
+
+	d := dispatcher.Load()
+	if d == nil {
+		return h.write(ctx, r)
+	}
+	// fatal 日志之后进程通常会退出，先写完队列再同步写入
+	if r.Level >= LevelFatal {
+		d.flush(time.Second)
+		return h.write(ctx, r)
+	}
+	// 记录在队列中等待写入，ctx 取消不应影响写入
+	d.enqueue(entry{handler: h, ctx: context.WithoutCancel(ctx), record: r.Clone()})
+	return nil
+}
+
+// write 根据日志级别写入控制台和不同的文件
+func (h *MultiLevelHandler) write(ctx context.Context, r slog.Record) error {
+	// 输出到控制台
+	if h.consoleColorHandler != nil && h.consoleColorHandler.Enabled(ctx, r.Level) {
+		if err := h.consoleColorHandler.Handle(ctx, r); err != nil {
 			return err
 		}
 	}
-	// error 级别及以上日志写入 error.log
-	if r.Level >= slog.LevelError {
-		if err := h.errorHandler.Handle(ctx, r); err != nil {
+	// 未启用文件输出
+	if h.debugHandler == nil {
+		return nil
+	}
+	// 当前等级及以上的日志都写入 debug.log
+	// info 级别及以上日志写入 info.log，error 级别及以上日志写入 error.log
+	// 各文件的等级都跟随 levelVar，运行时修改等级后立即生效
+	for _, sub := range []slog.Handler{h.debugHandler, h.infoHandler, h.errorHandler} {
+		if !sub.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := sub.Handle(ctx, r); err != nil {
 			return err
 		}
 	}
@@ -189,19 +206,57 @@ func (h *MultiLevelHandler) Handle(ctx context.Context, r slog.Record) error {
 
 // WithAttrs 实现 slog.Handler 接口
 func (h *MultiLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &MultiLevelHandler{
-		debugHandler: h.debugHandler.WithAttrs(attrs).(*slog.JSONHandler),
-		infoHandler:  h.infoHandler.WithAttrs(attrs).(*slog.JSONHandler),
-		errorHandler: h.errorHandler.WithAttrs(attrs).(*slog.JSONHandler),
+	if len(attrs) == 0 {
+		return h
 	}
+	return h.with(func(sub slog.Handler) slog.Handler { return sub.WithAttrs(attrs) }, false)
 }
 
 // WithGroup 实现 slog.Handler 接口
 func (h *MultiLevelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(sub slog.Handler) slog.Handler { return sub.WithGroup(name) }, true)
+}
+
+// with 派生新的处理器，进入分组后记录派生操作，以便 withTopLevel 重放
+func (h *MultiLevelHandler) with(fn func(slog.Handler) slog.Handler, group bool) *MultiLevelHandler {
+	next := h.derive(fn)
+	if h.ungrouped == nil && !group {
+		return next
+	}
+	next.ungrouped = h.ungrouped
+	if next.ungrouped == nil {
+		next.ungrouped = h
+	}
+	next.grouped = append(slices.Clone(h.grouped), fn)
+	return next
+}
+
+// withTopLevel 在分组前的处理器上加入 attrs 后重放分组，记录中的属性加入分组后会落在组内
+func (h *MultiLevelHandler) withTopLevel(attrs []slog.Attr) *MultiLevelHandler {
+	next := h.ungrouped.derive(func(sub slog.Handler) slog.Handler { return sub.WithAttrs(attrs) })
+	for _, fn := range h.grouped {
+		next = next.derive(fn)
+	}
+	return next
+}
+
+// derive 对每个已启用的输出应用 fn，返回新的处理器
+func (h *MultiLevelHandler) derive(fn func(slog.Handler) slog.Handler) *MultiLevelHandler {
+	apply := func(sub slog.Handler) slog.Handler {
+		if sub == nil {
+			return nil
+		}
+		return fn(sub)
+	}
 	return &MultiLevelHandler{
-		debugHandler: h.debugHandler.WithGroup(name).(*slog.JSONHandler),
-		infoHandler:  h.infoHandler.WithGroup(name).(*slog.JSONHandler),
-		errorHandler: h.errorHandler.WithGroup(name).(*slog.JSONHandler),
+		debugHandler:        apply(h.debugHandler),
+		infoHandler:         apply(h.infoHandler),
+		errorHandler:        apply(h.errorHandler),
+		consoleColorHandler: apply(h.consoleColorHandler),
+		redactor:            h.redactor,
 	}
 }
 
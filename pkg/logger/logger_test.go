@@ -0,0 +1,382 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("WARN")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestOutputs(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	NewMultiLevelHandler(Config{Path: filepath.Join(dir, "none"), Level: slog.LevelInfo, Output: OutputNone, MaxSize: 1})
+	slog.Info("不输出")
+	_, err := os.Stat(filepath.Join(dir, "none"))
+	assert.True(t, os.IsNotExist(err))
+
+	NewMultiLevelHandler(Config{Path: filepath.Join(dir, "file"), Level: slog.LevelInfo, Output: OutputFile, MaxSize: 1})
+	slog.Debug("低于日志等级")
+	slog.Info("写入文件")
+	slog.Error("写入错误日志")
+
+	info, err := os.ReadFile(filepath.Join(dir, "file", "info.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(info), "写入文件")
+	assert.NotContains(t, string(info), "低于日志等级")
+
+	errorLog, err := os.ReadFile(filepath.Join(dir, "file", "error.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(errorLog), "写入错误日志")
+	assert.NotContains(t, string(errorLog), "写入文件")
+}
+
+func TestRuntimeLevel(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	NewMultiLevelHandler(Config{Path: dir, Level: slog.LevelWarn, Output: OutputFile, MaxSize: 1})
+	slog.Info("修改前")
+
+	var mu sync.Mutex
+	var changes []slog.Level
+	OnLevelChange(func(level slog.Level) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, level)
+	})
+
+	SetLevel(slog.LevelDebug)
+	slog.Debug("修改后")
+	slog.Info("修改后 info")
+
+	debugLog, err := os.ReadFile(filepath.Join(dir, "debug.log"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(debugLog), "修改前")
+	assert.Contains(t, string(debugLog), "修改后")
+
+	info, err := os.ReadFile(filepath.Join(dir, "info.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(info), "修改后 info")
+	assert.NotContains(t, string(info), `"msg":"修改后"`, "info.log 不写入 debug 日志")
+
+	// 临时等级到期后恢复
+	SetLevelFor(slog.LevelError, 20*time.Millisecond, slog.LevelInfo)
+	assert.Equal(t, slog.LevelError, GetLevel())
+	assert.False(t, RevertAt().IsZero())
+	assert.Eventually(t, func() bool { return GetLevel() == slog.LevelInfo }, time.Second, 5*time.Millisecond)
+	assert.True(t, RevertAt().IsZero())
+
+	// SetLevel 取消未到期的恢复
+	SetLevelFor(slog.LevelError, 20*time.Millisecond, slog.LevelInfo)
+	SetLevel(slog.LevelWarn)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, slog.LevelWarn, GetLevel())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []slog.Level{slog.LevelDebug, slog.LevelError, slog.LevelInfo, slog.LevelError, slog.LevelWarn}, changes)
+	assert.Equal(t, "warn", LevelName(slog.LevelWarn))
+	assert.Equal(t, "fatal", LevelName(LevelFatal))
+}
+
+func TestDerivedHandlerKeepsSinks(t *testing.T) {
+	SetLevel(slog.LevelDebug)
+	var console, debugLog, errorLog bytes.Buffer
+	h := &MultiLevelHandler{
+		consoleColorHandler: slog.NewTextHandler(&console, &slog.HandlerOptions{Level: levelVar}),
+		debugHandler:        slog.NewJSONHandler(&debugLog, &slog.HandlerOptions{Level: levelVar}),
+		infoHandler:         slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: atLeast(slog.LevelInfo)}),
+		errorHandler:        slog.NewJSONHandler(&errorLog, &slog.HandlerOptions{Level: atLeast(slog.LevelError)}),
+	}
+
+	log := slog.New(h).With("doc_id", 7).WithGroup("call")
+	log.Debug("呼叫", "number", 12)
+	assert.Contains(t, console.String(), "doc_id=7 call.number=12")
+	assert.Contains(t, debugLog.String(), `"doc_id":7,"call":{"number":12}`)
+	assert.Empty(t, errorLog.String())
+
+	// 派生的处理器同样跟随运行时等级
+	SetLevel(slog.LevelWarn)
+	log.Info("不输出")
+	assert.NotContains(t, console.String(), "不输出")
+	assert.NotContains(t, debugLog.String(), "不输出")
+
+	// 未启用的输出保持为空
+	bare := (&MultiLevelHandler{consoleColorHandler: slog.NewTextHandler(&console, nil)}).WithAttrs([]slog.Attr{slog.Int("a", 1)})
+	assert.NoError(t, bare.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelWarn, "仅控制台", 0)))
+	assert.Contains(t, console.String(), "仅控制台 a=1")
+}
+
+func TestContextAttrs(t *testing.T) {
+	SetLevel(slog.LevelDebug)
+	var buf bytes.Buffer
+	log := slog.New(&MultiLevelHandler{debugHandler: slog.NewJSONHandler(&buf, nil), infoHandler: slog.NewJSONHandler(io.Discard, nil), errorHandler: slog.NewJSONHandler(io.Discard, nil)})
+
+	ctx := WithClientID(context.Background(), "client-1")
+	ctx = WithDocID(ctx, 7)
+	ctx = WithTraceID(ctx, "abc")
+	ctx = WithTraceID(ctx, "def")
+	log.InfoContext(ctx, "呼叫")
+	assert.Contains(t, buf.String(), `"client_id":"client-1","doc_id":7,"trace_id":"def"`)
+	assert.NotContains(t, buf.String(), "abc")
+
+	// 分组内记录时 context 属性仍在顶层
+	buf.Reset()
+	log.With("app", "call").WithGroup("req").With("path", "/call").InfoContext(ctx, "分组", "id", 1)
+	assert.Contains(t, buf.String(), `"app":"call","client_id":"client-1","doc_id":7,"trace_id":"def","req":{"path":"/call","id":1}`)
+
+	assert.Len(t, NewTraceID(), 16)
+	assert.Nil(t, AttrsFromContext(context.Background()))
+}
+
+// blockingHandler 在 release 关闭前阻塞写入，用于模拟磁盘缓慢
+type blockingHandler struct {
+	slog.Handler
+	release chan struct{}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, r slog.Record) error {
+	<-h.release
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestAsyncFlush(t *testing.T) {
+	defer Close(time.Second)
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	NewMultiLevelHandler(Config{Path: dir, Level: slog.LevelInfo, Output: OutputFile, MaxSize: 1, QueueSize: 16, Overflow: OverflowBlock})
+	for i := 0; i < 100; i++ {
+		slog.Info("异步写入", "i", i)
+	}
+	assert.NoError(t, Flush(time.Second))
+
+	info, err := os.ReadFile(filepath.Join(dir, "info.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, bytes.Count(info, []byte("异步写入")), "block 策略不丢日志")
+	assert.Zero(t, Dropped())
+
+	// 关闭后文件句柄已释放，之后同步写入时重新打开
+	assert.NoError(t, Close(time.Second))
+	for _, w := range files.Load().writers {
+		assert.Nil(t, w.file)
+	}
+	slog.Info("关闭后")
+	info, _ = os.ReadFile(filepath.Join(dir, "info.log"))
+	assert.Contains(t, string(info), "关闭后")
+}
+
+func TestAsyncDrop(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	SetLevel(slog.LevelDebug)
+
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	release := make(chan struct{})
+	h := &MultiLevelHandler{consoleColorHandler: &blockingHandler{
+		Handler: slog.NewTextHandler(writerFunc(func(p []byte) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return buf.Write(p)
+		}), nil),
+		release: release,
+	}}
+	slog.SetDefault(slog.New(h))
+
+	d := newAsyncDispatcher(1, OverflowDrop)
+	dispatcher.Store(d)
+
+	// 第一条被分发 goroutine 取走后阻塞，第二条占满队列
+	slog.Info("第一条")
+	assert.Eventually(t, func() bool { return len(d.queue) == 0 }, time.Second, time.Millisecond)
+	slog.Info("第二条")
+	slog.Info("丢弃")
+	slog.Debug("丢弃")
+	assert.Equal(t, uint64(2), Dropped())
+
+	// error 不丢弃，等待队列有空位
+	errDone := make(chan struct{})
+	go func() {
+		slog.Error("错误")
+		close(errDone)
+	}()
+	select {
+	case <-errDone:
+		t.Fatal("队列已满时 error 应等待")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-errDone
+
+	// 关闭时写完剩余日志并汇报丢弃数量
+	assert.NoError(t, Close(time.Second))
+
+	mu.Lock()
+	defer mu.Unlock()
+	out := buf.String()
+	assert.Contains(t, out, "第二条")
+	assert.Contains(t, out, "错误")
+	assert.NotContains(t, out, "丢弃\n")
+	assert.Contains(t, out, "日志队列已满，部分日志已丢弃 debug=1 info=1")
+}
+
+func TestAsyncCloseWhileLogging(t *testing.T) {
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	h := &MultiLevelHandler{consoleColorHandler: slog.NewTextHandler(writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.Write(p)
+	}), nil)}
+
+	// 与关闭并发入队的 error 日志都要写入，不能留在队列中
+	d := newAsyncDispatcher(4, OverflowDrop)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				d.enqueue(entry{handler: h, ctx: context.Background(), record: slog.NewRecord(time.Now(), slog.LevelError, "关闭时", 0)})
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, d.close(time.Second))
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 20*200, strings.Count(buf.String(), "关闭时"))
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestStringSourceAttr(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	NewMultiLevelHandler(Config{Path: dir, Level: slog.LevelInfo, Output: OutputBoth, MaxSize: 1})
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "前端日志", 0)
+	r.AddAttrs(slog.String(slog.SourceKey, "frontend"))
+	assert.NoError(t, slog.Default().Handler().Handle(context.Background(), r))
+
+	info, err := os.ReadFile(filepath.Join(dir, "info.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(info), `"source":"frontend"`)
+}
+
+type patient struct {
+	Name    string  `json:"patient_name"`
+	IDCard  string  `json:"id_card"`
+	Remark  string  `json:"remark"`
+	Contact contact `json:"contact"`
+}
+
+type contact struct {
+	Mobile int64  `json:"mobile_no"`
+	Note   string `json:"note"`
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor(RedactConfig{
+		Keys:     []string{"password", "id_card"},
+		NameKeys: []string{"patient_name"},
+		IDCard:   true,
+		Phone:    true,
+		Patterns: []string{`MRN\d+`},
+	})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: r.ReplaceAttr}))
+	log.Info("呼叫患者",
+		"PatientName", "欧阳小明",
+		"password", "123456",
+		"phone", int64(13812345678),
+		slog.Group("call",
+			slog.Int("number", 12),
+			slog.Group("patient", slog.String("patient_name", "张三"), slog.String("remark", "身份证 110101199003071234")),
+		),
+		"patient", patient{
+			Name:    "张小三",
+			IDCard:  "110101199003071234",
+			Remark:  "病历号 MRN0042",
+			Contact: contact{Mobile: 13912345678, Note: "家属 15012345678"},
+		},
+		"patients", []map[string]any{{"patient_name": "李四", "number": 3}},
+	)
+	out := buf.String()
+
+	assert.Contains(t, out, `"PatientName":"欧**明"`)
+	assert.Contains(t, out, `"password":"******"`)
+	assert.Contains(t, out, `"phone":"138****5678"`)
+	assert.Contains(t, out, `"call":{"number":12,"patient":{"patient_name":"张*","remark":"身份证 110101********1234"}}`)
+	assert.Contains(t, out, `"patient":{"contact":{"mobile_no":"139****5678","note":"家属 150****5678"},"id_card":"******","patient_name":"张*三","remark":"病历号 ******"}`)
+	assert.Contains(t, out, `"patients":[{"number":3,"patient_name":"李*"}]`)
+	assert.NotContains(t, out, "13812345678")
+
+	// 内置字段和非中文姓名不处理
+	assert.Equal(t, "call", MaskName("call"))
+	assert.Equal(t, slog.String(slog.MessageKey, "13812345678"), r.ReplaceAttr(nil, slog.String(slog.MessageKey, "13812345678")))
+
+	_, err = NewRedactor(RedactConfig{Patterns: []string{"("}})
+	assert.Error(t, err)
+}
+
+func TestRedactAllSinks(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	// 控制台输出写入 os.Stdout，替换为管道后读取
+	stdout := os.Stdout
+	pr, pw, err := os.Pipe()
+	assert.NoError(t, err)
+	os.Stdout = pw
+	defer func() { os.Stdout = stdout }()
+
+	redactor, err := NewRedactor(RedactConfig{Keys: []string{"token"}, NameKeys: []string{"name"}, IDCard: true, Phone: true})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	NewMultiLevelHandler(Config{Path: dir, Level: slog.LevelDebug, Output: OutputBoth, MaxSize: 1, Redactor: redactor})
+
+	log := slog.With("token", "abc123")
+	log.Error("患者 13812345678 登记失败", "name", "王小二", "id", "110101199003071234")
+	os.Stdout = stdout
+	assert.NoError(t, pw.Close())
+	console, err := io.ReadAll(pr)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"debug.log", "info.log", "error.log"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "患者 138****5678 登记失败", name)
+		assert.Contains(t, string(data), `"token":"******"`, name)
+		assert.Contains(t, string(data), `"name":"王*二"`, name)
+		assert.Contains(t, string(data), `"id":"110101********1234"`, name)
+	}
+	for _, s := range []string{"13812345678", "abc123", "王小二", "110101199003071234"} {
+		assert.NotContains(t, string(console), s)
+	}
+	assert.Contains(t, string(console), "138****5678")
+}
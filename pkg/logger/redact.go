@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Masked 按键名隐藏的值
+const Masked = "******"
+
+// RedactConfig 日志脱敏规则
+type RedactConfig struct {
+	Keys     []string // 值整体隐藏的键名
+	NameKeys []string // 姓名键，只保留首尾字，例如 张*三
+	IDCard   bool     // 隐藏身份证号中间部分
+	Phone    bool     // 隐藏手机号中间四位
+	Patterns []string // 额外的正则，匹配部分整体隐藏
+}
+
+// 内置规则：身份证号保留前 6 位和后 4 位，手机号保留前 3 位和后 4 位
+var (
+	idCardPattern = regexp.MustCompile(`\b(\d{6})\d{8}(\d{3}[\dXx])\b`)
+	phonePattern  = regexp.MustCompile(`\b(1[3-9]\d)\d{4}(\d{4})\b`)
+)
+
+// rule 字符串脱敏规则
+type rule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// Redactor 日志脱敏，作为 ReplaceAttr 链的一环作用于所有输出
+type Redactor struct {
+	keys     map[string]bool
+	nameKeys map[string]bool
+	rules    []rule
+}
+
+// NewRedactor 根据规则创建脱敏器，正则无效时返回错误
+func NewRedactor(cfg RedactConfig) (*Redactor, error) {
+	r := &Redactor{
+		keys:     make(map[string]bool, len(cfg.Keys)),
+		nameKeys: make(map[string]bool, len(cfg.NameKeys)),
+	}
+	for _, key := range cfg.Keys {
+		r.keys[normalizeKey(key)] = true
+	}
+	for _, key := range cfg.NameKeys {
+		r.nameKeys[normalizeKey(key)] = true
+	}
+
+	if cfg.IDCard {
+		r.rules = append(r.rules, rule{idCardPattern, "${1}********${2}"})
+	}
+	if cfg.Phone {
+		r.rules = append(r.rules, rule{phonePattern, "${1}****${2}"})
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("脱敏正则 %q 无效: %w", pattern, err)
+		}
+		r.rules = append(r.rules, rule{re, Masked})
+	}
+	return r, nil
+}
+
+// normalizeKey 键名不区分大小写，忽略下划线和连字符，patient_name 与 patientName 视为相同
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// ReplaceAttr 实现 slog.HandlerOptions.ReplaceAttr，分组中的属性按最后一级键名匹配
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.TimeKey, slog.LevelKey, slog.SourceKey, slog.MessageKey:
+			return a
+		}
+	}
+	a.Value = r.value(a.Key, a.Value)
+	return a
+}
+
+// String 对字符串应用正则规则
+func (r *Redactor) String(s string) string {
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllString(s, rule.repl)
+	}
+	return s
+}
+
+// value 按键名和值类型脱敏
+func (r *Redactor) value(key string, v slog.Value) slog.Value {
+	v = v.Resolve()
+	if v.Kind() == slog.KindGroup {
+		attrs := v.Group()
+		result := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			result[i] = slog.Attr{Key: a.Key, Value: r.value(a.Key, a.Value)}
+		}
+		return slog.GroupValue(result...)
+	}
+
+	normalized := normalizeKey(key)
+	if r.keys[normalized] {
+		return slog.StringValue(Masked)
+	}
+
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		if r.nameKeys[normalized] {
+			s = MaskName(s)
+		}
+		return slog.StringValue(r.String(s))
+	case slog.KindInt64, slog.KindUint64:
+		// 以数字记录的手机号
+		s := v.String()
+		if masked := r.String(s); masked != s {
+			return slog.StringValue(masked)
+		}
+		return v
+	case slog.KindAny:
+		return r.anyValue(v)
+	}
+	return v
+}
+
+// anyValue 处理结构体、map 和切片，按 JSON 字段名逐层脱敏，error 和 Stringer 只处理文本
+func (r *Redactor) anyValue(v slog.Value) slog.Value {
+	raw := v.Any()
+	if raw == nil {
+		return v
+	}
+	if err, ok := raw.(error); ok {
+		return r.text(v, err.Error())
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(raw)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		if s, ok := raw.(fmt.Stringer); ok {
+			return r.text(v, s.String())
+		}
+		return v
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return v
+	}
+	return slog.AnyValue(r.walk("", doc))
+}
+
+// text 文本中有需要隐藏的内容时返回脱敏后的字符串，否则保留原值
+func (r *Redactor) text(v slog.Value, s string) slog.Value {
+	if masked := r.String(s); masked != s {
+		return slog.StringValue(masked)
+	}
+	return v
+}
+
+// walk 递归处理 JSON 解码后的值
+func (r *Redactor) walk(key string, v any) any {
+	normalized := normalizeKey(key)
+	if key != "" && r.keys[normalized] {
+		return Masked
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			x[k] = r.walk(k, item)
+		}
+		return x
+	case []any:
+		for i, item := range x {
+			x[i] = r.walk(key, item)
+		}
+		return x
+	case string:
+		if r.nameKeys[normalized] {
+			x = MaskName(x)
+		}
+		return r.String(x)
+	case json.Number:
+		if masked := r.String(x.String()); masked != x.String() {
+			return masked
+		}
+		return x
+	}
+	return v
+}
+
+// MaskName 隐藏姓名中间的字：张三 → 张*，张小三 → 张*三，欧阳小明 → 欧**明
+// 不含汉字的值（例如提示音名称）不处理
+func MaskName(name string) string {
+	runes := []rune(name)
+	hasHan := false
+	for _, c := range runes {
+		if unicode.Is(unicode.Han, c) {
+			hasHan = true
+			break
+		}
+	}
+	if !hasHan || len(runes) < 2 {
+		return name
+	}
+	if len(runes) == 2 {
+		return string(runes[0]) + "*"
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+}
@@ -0,0 +1,425 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backupDateFormat 轮转文件名中的日期，例如 info-2024-06-01.log、info-2024-06-01.1.log
+	backupDateFormat = "2006-01-02"
+	// legacyTimeFormat lumberjack 轮转文件名中的时间，升级前留下的旧文件仍参与查询和清理
+	legacyTimeFormat = "2006-01-02T15-04-05.000"
+	// compressSuffix 压缩后的文件后缀
+	compressSuffix = ".gz"
+	// rotateRetryInterval 轮转失败后的重试间隔，文件被占用期间不在每次写入时重试
+	rotateRetryInterval = time.Minute
+)
+
+// Backup 轮转后的日志文件
+type Backup struct {
+	Name   string    // 文件名
+	Base   string    // 所属日志，debug、info、error
+	Time   time.Time // 文件中日志的日期，旧版文件为轮转时间
+	Seq    int       // 同一天内的序号，从 0 开始
+	legacy bool
+}
+
+// ParseBackup 解析轮转文件名，不是轮转文件时返回 false
+func ParseBackup(name string) (Backup, bool) {
+	stem := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ".log")
+	if stem == name {
+		return Backup{}, false
+	}
+	base, stamp, ok := strings.Cut(stem, "-")
+	if !ok || base == "" {
+		return Backup{}, false
+	}
+
+	b := Backup{Name: name, Base: base}
+	// lumberjack 默认使用 UTC 时间命名
+	if t, err := time.Parse(legacyTimeFormat, stamp); err == nil {
+		b.Time, b.legacy = t, true
+		return b, true
+	}
+	date, seq, hasSeq := strings.Cut(stamp, ".")
+	t, err := time.ParseInLocation(backupDateFormat, date, time.Local)
+	if err != nil {
+		return Backup{}, false
+	}
+	b.Time = t
+	if hasSeq {
+		if b.Seq, err = strconv.Atoi(seq); err != nil || b.Seq < 1 {
+			return Backup{}, false
+		}
+	}
+	return b, true
+}
+
+// Before 按日志的先后顺序比较，同一天升级前的旧版文件排在前面
+func (b Backup) Before(o Backup) bool {
+	if d1, d2 := b.Time.Format(backupDateFormat), o.Time.Format(backupDateFormat); d1 != d2 {
+		return d1 < d2
+	}
+	if b.legacy != o.legacy {
+		return b.legacy
+	}
+	if !b.Time.Equal(o.Time) {
+		return b.Time.Before(o.Time)
+	}
+	return b.Seq < o.Seq
+}
+
+// backupName 生成轮转文件名
+func backupName(base string, day time.Time, seq int) string {
+	if seq == 0 {
+		return fmt.Sprintf("%s-%s.log", base, day.Format(backupDateFormat))
+	}
+	return fmt.Sprintf("%s-%s.%d.log", base, day.Format(backupDateFormat), seq)
+}
+
+// startOfDay 返回 t 所在日期的零点
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// rotateSet 同一目录下的日志文件，共享清理规则和磁盘空间上限
+type rotateSet struct {
+	dir        string
+	maxSize    int64 // 单个文件大小上限，0 表示不限
+	daily      bool
+	maxBackups int   // 每个日志保留的轮转文件数量，0 表示不限
+	maxAge     int   // 轮转文件保留天数，0 表示不限
+	maxTotal   int64 // 所有日志文件的总大小上限，0 表示不限
+	compress   bool
+	now        func() time.Time
+
+	bases   []string
+	writers []*RotateWriter
+	mu      sync.Mutex // 同一时间只有一个清理任务
+	wg      sync.WaitGroup
+}
+
+func newRotateSet(cfg Config) *rotateSet {
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &rotateSet{
+		dir:        cfg.Path,
+		maxSize:    int64(cfg.MaxSize) << 20,
+		daily:      cfg.Daily,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     cfg.MaxAge,
+		maxTotal:   int64(cfg.MaxTotal) << 20,
+		compress:   cfg.Compress,
+		now:        now,
+	}
+}
+
+// writer 创建 base.log 的写入器
+func (s *rotateSet) writer(base string) *RotateWriter {
+	w := &RotateWriter{set: s, base: base}
+	s.bases = append(s.bases, base)
+	s.writers = append(s.writers, w)
+	return w
+}
+
+// close 等待后台清理结束后关闭所有文件，之后写入时重新打开
+func (s *rotateSet) close(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	var errs []error
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		errs = append(errs, ErrFlushTimeout)
+	}
+	for _, w := range s.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RotateWriter 按日期和大小轮转的日志文件，当前文件固定为 base.log
+type RotateWriter struct {
+	set  *rotateSet
+	base string
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	day     time.Time // 当前文件中日志的日期
+	retryAt time.Time // 轮转失败后，在此之前不再重试
+}
+
+func (w *RotateWriter) path() string {
+	return filepath.Join(w.set.dir, w.base+".log")
+}
+
+// Write 实现 io.Writer，日期变化或超过大小上限时先轮转
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.set.now()
+	if w.file == nil {
+		if err := w.open(now); err != nil {
+			return 0, err
+		}
+	}
+	newDay := w.set.daily && !w.day.Equal(startOfDay(now))
+	full := w.set.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.set.maxSize
+	if (newDay || full) && !now.Before(w.retryAt) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// open 打开当前文件，已有内容时按最后修改时间确定日期
+func (w *RotateWriter) open(now time.Time) error {
+	if err := os.MkdirAll(w.set.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file, w.size, w.day = f, info.Size(), startOfDay(now)
+	if info.Size() > 0 {
+		w.day = startOfDay(info.ModTime().In(now.Location()))
+	}
+	return nil
+}
+
+// rotate 将当前文件重命名为带日期的文件并新建当前文件
+// 重命名失败（例如文件被其他程序占用）时继续写入当前文件，不丢日志，保留原日期并在 rotateRetryInterval 后重试
+func (w *RotateWriter) rotate(now time.Time) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	name := backupName(w.base, w.day, w.set.nextSeq(w.base, w.day))
+	if err := os.Rename(w.path(), filepath.Join(w.set.dir, name)); err != nil {
+		// 日志自身的错误不能再写入日志，输出到标准错误
+		fmt.Fprintln(os.Stderr, "日志轮转失败:", err)
+		day := w.day
+		if err := w.open(now); err != nil {
+			return err
+		}
+		w.day = day
+		w.retryAt = now.Add(rotateRetryInterval)
+		return nil
+	}
+	if err := w.open(now); err != nil {
+		return err
+	}
+	w.retryAt = time.Time{}
+
+	w.set.startMill()
+	return nil
+}
+
+// Close 关闭当前文件
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// nextSeq 返回当天下一个可用的序号，保证序号与写入顺序一致
+func (s *rotateSet) nextSeq(base string, day time.Time) int {
+	seq := 0
+	for _, b := range s.backups() {
+		if b.Base == base && !b.legacy && b.Time.Format(backupDateFormat) == day.Format(backupDateFormat) && b.Seq >= seq {
+			seq = b.Seq + 1
+		}
+	}
+	return seq
+}
+
+// backups 返回目录中属于本组日志的轮转文件，按时间从旧到新排列
+func (s *rotateSet) backups() []Backup {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil
+	}
+	var backups []Backup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		b, ok := ParseBackup(entry.Name())
+		if !ok || !s.owns(b.Base) {
+			continue
+		}
+		backups = append(backups, b)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Before(backups[j]) })
+	return backups
+}
+
+func (s *rotateSet) owns(base string) bool {
+	for _, b := range s.bases {
+		if b == base {
+			return true
+		}
+	}
+	return false
+}
+
+// startMill 在后台清理，压缩较大的文件时不阻塞写入
+func (s *rotateSet) startMill() {
+	s.wg.Add(1)
+	go s.mill()
+}
+
+// mill 清理过期和超出数量的文件，压缩旧文件，最后按总大小删除最旧的文件
+func (s *rotateSet) mill() {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.cleanup(); err != nil {
+		fmt.Fprintln(os.Stderr, "清理日志文件失败:", err)
+	}
+}
+
+func (s *rotateSet) cleanup() error {
+	backups := s.backups()
+	cutoff := startOfDay(s.now()).AddDate(0, 0, -s.maxAge)
+
+	// 从新到旧统计每个日志已保留的数量
+	kept := make(map[string]int)
+	var remain []Backup
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		expired := s.maxAge > 0 && b.Time.Before(cutoff)
+		excess := s.maxBackups > 0 && kept[b.Base] >= s.maxBackups
+		if expired || excess {
+			if err := s.remove(b.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		kept[b.Base]++
+		remain = append([]Backup{b}, remain...)
+	}
+
+	if s.compress {
+		for i, b := range remain {
+			if strings.HasSuffix(b.Name, compressSuffix) {
+				continue
+			}
+			if err := compressFile(filepath.Join(s.dir, b.Name)); err != nil {
+				return err
+			}
+			remain[i].Name += compressSuffix
+		}
+	}
+
+	if s.maxTotal <= 0 {
+		return nil
+	}
+	// 当前文件不能删除，但计入总大小
+	var total int64
+	for _, base := range s.bases {
+		total += fileSize(filepath.Join(s.dir, base+".log"))
+	}
+	for _, b := range remain {
+		total += fileSize(filepath.Join(s.dir, b.Name))
+	}
+	for _, b := range remain {
+		if total <= s.maxTotal {
+			break
+		}
+		size := fileSize(filepath.Join(s.dir, b.Name))
+		if err := s.remove(b.Name); err != nil {
+			return err
+		}
+		total -= size
+	}
+	return nil
+}
+
+func (s *rotateSet) remove(name string) error {
+	err := os.Remove(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// compressFile 将文件压缩为 .gz 并删除原文件
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock 可手动调整的时钟
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock(t time.Time) *testClock {
+	return &testClock{now: t}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func day(d, hour, minute int) time.Time {
+	return time.Date(2024, 6, d, hour, minute, 0, 0, time.Local)
+}
+
+// listDir 返回目录中的文件名
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestRotateDaily(t *testing.T) {
+	dir := t.TempDir()
+	clock := newTestClock(day(1, 10, 0))
+	set := newRotateSet(Config{Path: dir, Daily: true, Now: clock.Now})
+	w := set.writer("info")
+	defer w.Close()
+
+	write := func(s string) {
+		_, err := w.Write([]byte(s + "\n"))
+		assert.NoError(t, err)
+	}
+	write("早上")
+	clock.Set(day(1, 23, 59))
+	write("午夜前")
+	clock.Set(day(2, 0, 0))
+	write("第二天")
+
+	// 没有日志的日期不生成文件
+	clock.Set(day(5, 8, 0))
+	write("第五天")
+	set.wg.Wait()
+
+	assert.Equal(t, []string{"info-2024-06-01.log", "info-2024-06-02.log", "info.log"}, listDir(t, dir))
+	assert.Equal(t, "早上\n午夜前\n", readFile(t, filepath.Join(dir, "info-2024-06-01.log")))
+	assert.Equal(t, "第二天\n", readFile(t, filepath.Join(dir, "info-2024-06-02.log")))
+	assert.Equal(t, "第五天\n", readFile(t, filepath.Join(dir, "info.log")))
+}
+
+func TestRotateSize(t *testing.T) {
+	dir := t.TempDir()
+	clock := newTestClock(day(1, 10, 0))
+	set := newRotateSet(Config{Path: dir, Daily: true, Now: clock.Now})
+	set.maxSize = 10
+	w := set.writer("debug")
+	defer w.Close()
+
+	for _, s := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n"} {
+		_, err := w.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	clock.Set(day(2, 0, 0))
+	_, err := w.Write([]byte("dddddd\n"))
+	assert.NoError(t, err)
+	set.wg.Wait()
+
+	// 同一天按写入顺序编号
+	assert.Equal(t, []string{"debug-2024-06-01.1.log", "debug-2024-06-01.2.log", "debug-2024-06-01.log", "debug.log"}, listDir(t, dir))
+	assert.Equal(t, "aaaaaa\n", readFile(t, filepath.Join(dir, "debug-2024-06-01.log")))
+	assert.Equal(t, "bbbbbb\n", readFile(t, filepath.Join(dir, "debug-2024-06-01.1.log")))
+	assert.Equal(t, "cccccc\n", readFile(t, filepath.Join(dir, "debug-2024-06-01.2.log")))
+
+	var backups []Backup
+	for _, name := range listDir(t, dir) {
+		if b, ok := ParseBackup(name); ok {
+			backups = append(backups, b)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Before(backups[j]) })
+	assert.Equal(t, []int{0, 1, 2}, []int{backups[0].Seq, backups[1].Seq, backups[2].Seq})
+}
+
+func TestRotateExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "error.log")
+	assert.NoError(t, os.WriteFile(path, []byte("上次运行\n"), 0o644))
+	assert.NoError(t, os.Chtimes(path, day(3, 18, 0), day(3, 18, 0)))
+
+	// 启动后的第一条日志把前几天的文件轮转出去
+	set := newRotateSet(Config{Path: dir, Daily: true, Now: newTestClock(day(5, 9, 0)).Now})
+	w := set.writer("error")
+	defer w.Close()
+	_, err := w.Write([]byte("本次运行\n"))
+	assert.NoError(t, err)
+	set.wg.Wait()
+
+	assert.Equal(t, "上次运行\n", readFile(t, filepath.Join(dir, "error-2024-06-03.log")))
+	assert.Equal(t, "本次运行\n", readFile(t, path))
+}
+
+func TestRotateRetryAfterRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	clock := newTestClock(day(1, 10, 0))
+	set := newRotateSet(Config{Path: dir, Daily: true, Now: clock.Now})
+	w := set.writer("info")
+	defer w.Close()
+
+	write := func(s string) {
+		_, err := w.Write([]byte(s + "\n"))
+		assert.NoError(t, err)
+	}
+	write("第一天")
+
+	// 目标文件名被目录占用，重命名失败时继续写入当前文件
+	blocked := filepath.Join(dir, "info-2024-06-01.log")
+	assert.NoError(t, os.MkdirAll(filepath.Join(blocked, "x"), 0o755))
+	clock.Set(day(2, 9, 0))
+	write("第二天")
+	assert.Equal(t, "第一天\n第二天\n", readFile(t, filepath.Join(dir, "info.log")))
+
+	// 重试间隔内不再轮转，之后按原日期重试
+	assert.NoError(t, os.RemoveAll(blocked))
+	clock.Set(day(2, 9, 0).Add(rotateRetryInterval / 2))
+	write("间隔内")
+	_, err := os.Stat(blocked)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	clock.Set(day(2, 9, 0).Add(rotateRetryInterval))
+	write("重试后")
+	set.wg.Wait()
+	assert.Equal(t, "第一天\n第二天\n间隔内\n", readFile(t, blocked))
+	assert.Equal(t, "重试后\n", readFile(t, filepath.Join(dir, "info.log")))
+}
+
+func TestRotateCleanup(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{
+		"debug-2024-05-20T08-00-00.000.log.gz": 10, // 升级前的 lumberjack 文件
+		"debug-2024-06-07.log":                 10,
+		"debug-2024-06-08.log":                 10,
+		"debug-2024-06-09.log":                 10,
+		"debug-2024-06-09.1.log":               10,
+		"info-2024-06-08.log":                  10,
+		"info-2024-06-09.log.gz":               10,
+		"error-2024-06-01.log":                 10,
+		"debug.log":                            5,
+		"info.log":                             5,
+		"diagnostics-pc01.zip":                 10, // 其他文件不处理
+	}
+	for name, size := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", size)), 0o644))
+	}
+
+	set := newRotateSet(Config{Path: dir, MaxAge: 3, MaxBackups: 2, Now: newTestClock(day(10, 9, 0)).Now})
+	for _, base := range []string{"debug", "info", "error"} {
+		set.writer(base)
+	}
+	assert.NoError(t, set.cleanup())
+	// 超过 3 天的、每个日志超过 2 个的被删除
+	assert.Equal(t, []string{
+		"debug-2024-06-09.1.log", "debug-2024-06-09.log", "debug.log",
+		"diagnostics-pc01.zip",
+		"info-2024-06-08.log", "info-2024-06-09.log.gz", "info.log",
+	}, listDir(t, dir))
+
+	// 压缩后按总大小删除最旧的文件，当前文件不删除
+	set.compress = true
+	set.maxTotal = 45
+	assert.NoError(t, set.cleanup())
+	names := listDir(t, dir)
+	assert.Contains(t, names, "debug-2024-06-09.1.log.gz")
+	assert.Contains(t, names, "debug.log")
+	assert.Contains(t, names, "info.log")
+	assert.NotContains(t, names, "info-2024-06-08.log")
+	assert.NotContains(t, names, "info-2024-06-08.log.gz")
+
+	var total int64
+	for _, name := range names {
+		if name != "diagnostics-pc01.zip" {
+			total += fileSize(filepath.Join(dir, name))
+		}
+	}
+	assert.LessOrEqual(t, total, int64(45))
+}
+
+func TestParseBackup(t *testing.T) {
+	b, ok := ParseBackup("info-2024-06-01.2.log.gz")
+	assert.True(t, ok)
+	assert.Equal(t, "info", b.Base)
+	assert.Equal(t, 2, b.Seq)
+	assert.True(t, day(1, 0, 0).Equal(b.Time))
+
+	legacy, ok := ParseBackup("info-2024-06-01T09-00-00.000.log")
+	assert.True(t, ok)
+	assert.True(t, legacy.Before(b), "同一天升级前的文件在前")
+
+	for _, name := range []string{"info.log", "info-2024-06-01.0.log", "info-latest.log", "diagnostics-pc01.zip"} {
+		_, ok := ParseBackup(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestDailyRotateThroughHandler(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	clock := newTestClock(day(1, 23, 59))
+	NewMultiLevelHandler(Config{Path: dir, Level: slog.LevelInfo, Output: OutputFile, MaxSize: 1, Daily: true, Now: clock.Now})
+	slog.Error("第一天")
+	clock.Set(day(2, 0, 1))
+	slog.Info("第二天")
+
+	assert.Contains(t, readFile(t, filepath.Join(dir, "info-2024-06-01.log")), "第一天")
+	assert.Contains(t, readFile(t, filepath.Join(dir, "info.log")), "第二天")
+	// error.log 在下一条错误日志时才轮转
+	assert.Contains(t, readFile(t, filepath.Join(dir, "error.log")), "第一天")
+}
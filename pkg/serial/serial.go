@@ -0,0 +1,32 @@
+package serial
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrUnsupportedBaudRate 不支持的波特率
+	ErrUnsupportedBaudRate = errors.New("unsupported baud rate")
+	// ErrUnsupportedPlatform 当前平台不支持串口
+	ErrUnsupportedPlatform = errors.New("serial port is not supported on this platform")
+)
+
+// Config 串口配置，固定 8 位数据位、无校验、1 位停止位
+type Config struct {
+	Name     string // 设备名，例如 /dev/ttyUSB0 或 COM3
+	BaudRate int
+}
+
+// Port 串口
+type Port interface {
+	io.ReadWriteCloser
+}
+
+// Open 打开串口
+func Open(cfg Config) (Port, error) {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 9600
+	}
+	return openPort(cfg)
+}
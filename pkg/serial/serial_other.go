@@ -0,0 +1,7 @@
+//go:build !linux && !windows
+
+package serial
+
+func openPort(cfg Config) (Port, error) {
+	return nil, ErrUnsupportedPlatform
+}
@@ -0,0 +1,67 @@
+//go:build windows
+
+package serial
+
+import (
+	"os"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// dcbBinary DCB.Flags 中的 fBinary 位，Windows 要求必须置位
+	dcbBinary  = 0x00000001
+	noParity   = 0
+	oneStopBit = 0
+)
+
+// openPort 打开串口并设置波特率和超时
+func openPort(cfg Config) (Port, error) {
+	name := cfg.Name
+	if !strings.HasPrefix(name, `\\.\`) {
+		// COM10 及以上必须使用设备命名空间
+		name = `\\.\` + name
+	}
+
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := windows.CreateFile(path,
+		windows.GENERIC_READ|windows.GENERIC_WRITE,
+		0, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var dcb windows.DCB
+	dcb.DCBlength = uint32(unsafe.Sizeof(dcb))
+	if err := windows.GetCommState(handle, &dcb); err != nil {
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+	dcb.BaudRate = uint32(cfg.BaudRate)
+	dcb.Flags = dcbBinary
+	dcb.ByteSize = 8
+	dcb.Parity = noParity
+	dcb.StopBits = oneStopBit
+	if err := windows.SetCommState(handle, &dcb); err != nil {
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+
+	// 读操作立即返回已有数据，写操作最多等待 1 秒
+	timeouts := windows.CommTimeouts{
+		ReadIntervalTimeout:       ^uint32(0),
+		WriteTotalTimeoutConstant: 1000,
+	}
+	if err := windows.SetCommTimeouts(handle, &timeouts); err != nil {
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(handle), cfg.Name), nil
+}
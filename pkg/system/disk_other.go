@@ -0,0 +1,10 @@
+//go:build !windows
+
+package system
+
+import "github.com/shirou/gopsutil/v4/disk"
+
+// diskSerial 获取分区所在磁盘的序列号
+func diskSerial(partition disk.PartitionStat) (string, error) {
+	return disk.SerialNumber(partition.Device)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package system
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"golang.org/x/sys/windows"
+)
+
+// diskSerial 获取分区的卷序列号，不是物理磁盘序列号，重新格式化后会变化
+// gopsutil 在 Windows 上未实现 SerialNumber，读取物理磁盘序列号需要 WMI 或管理员权限，这里改用 GetVolumeInformation
+func diskSerial(partition disk.PartitionStat) (string, error) {
+	root, err := windows.UTF16PtrFromString(partition.Mountpoint + `\`)
+	if err != nil {
+		return "", err
+	}
+
+	var serial uint32
+	if err := windows.GetVolumeInformation(root, nil, 0, &serial, nil, nil, nil, 0); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08X", serial), nil
+}
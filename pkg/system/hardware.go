@@ -0,0 +1,120 @@
+package system
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+// ErrHardwareInfoUnavailable 无法获取硬件信息
+var ErrHardwareInfoUnavailable = errors.New("hardware info unavailable")
+
+// 虚拟网卡名称前缀，这些网卡的 MAC 地址不稳定，不参与硬件标识
+var virtualInterfacePrefixes = []string{
+	"docker", "veth", "br-", "virbr", "vmnet", "vboxnet", "utun", "tun", "tap",
+	"vethernet", "virtualbox", "vmware", "hyper-v", "zerotier", "tailscale", "wg",
+}
+
+// GetMachineID 获取操作系统提供的机器ID
+func GetMachineID() (string, error) {
+	id, err := host.HostID()
+	if err != nil {
+		return "", err
+	}
+
+	id = strings.TrimSpace(strings.ToLower(id))
+	if id == "" {
+		return "", ErrHardwareInfoUnavailable
+	}
+	return id, nil
+}
+
+// GetPrimaryMAC 获取主网卡的 MAC 地址
+// 只考虑板载的有线和无线网卡，跳过回环、虚拟网卡以及 USB 网卡、蓝牙 PAN 等可插拔设备，
+// 按网卡名称排序后取第一个，保证多次调用结果一致
+func GetPrimaryMAC() (string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	var candidates []net.Interface
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 || len(iface.HardwareAddr) == 0 {
+			continue
+		}
+		if isVirtualInterface(iface.Name) {
+			continue
+		}
+		candidates = append(candidates, iface)
+	}
+	candidates = physicalInterfaces(candidates)
+
+	if len(candidates) == 0 {
+		return "", ErrHardwareInfoUnavailable
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return strings.ToLower(candidates[0].HardwareAddr.String()), nil
+}
+
+// GetDiskSerial 获取系统盘标识
+// Linux 等系统为系统盘所在磁盘的序列号；Windows 为系统卷（C:）的卷序列号，重新格式化后会变化
+func GetDiskSerial() (string, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return "", err
+	}
+
+	systemMount := "/"
+	if runtime.GOOS == "windows" {
+		systemMount = "C:"
+	}
+
+	for _, partition := range partitions {
+		if !strings.EqualFold(strings.TrimRight(partition.Mountpoint, `\`), systemMount) {
+			continue
+		}
+
+		serial, err := diskSerial(partition)
+		if err != nil {
+			return "", err
+		}
+		serial = strings.TrimSpace(serial)
+		if serial == "" {
+			return "", ErrHardwareInfoUnavailable
+		}
+		return serial, nil
+	}
+
+	return "", ErrHardwareInfoUnavailable
+}
+
+// isVirtualInterface 判断网卡是否为虚拟网卡
+func isVirtualInterface(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny 判断 s 是否包含任一关键字
+func containsAny(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package system
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// physicalInterfaces 只保留板载（PCI 等总线）的有线和无线网卡
+// 通过 /sys/class/net 判断：没有 device 的是虚拟网卡，挂在 USB 或蓝牙下的是可插拔网卡
+func physicalInterfaces(interfaces []net.Interface) []net.Interface {
+	var result []net.Interface
+	for _, iface := range interfaces {
+		dir := filepath.Join("/sys/class/net", iface.Name)
+		device, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+		if err != nil {
+			continue
+		}
+		if strings.Contains(device, "/usb") || strings.Contains(device, "/bluetooth") {
+			continue
+		}
+		// ARPHRD_ETHER，无线网卡同样为 1
+		if data, err := os.ReadFile(filepath.Join(dir, "type")); err != nil || strings.TrimSpace(string(data)) != "1" {
+			continue
+		}
+		result = append(result, iface)
+	}
+	return result
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package system
+
+import "net"
+
+// physicalInterfaces 其他系统无法区分总线类型，只按名称排除虚拟网卡
+func physicalInterfaces(interfaces []net.Interface) []net.Interface {
+	return interfaces
+}
@@ -0,0 +1,69 @@
+//go:build windows
+
+package system
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// 可插拔或虚拟网卡的描述关键字，例如 Bluetooth Device (Personal Area Network)、Realtek USB GbE Family Controller
+var excludedAdapterKeywords = []string{
+	"bluetooth", "usb", "virtual", "vpn", "tap-", "hyper-v", "loopback", "miniport",
+}
+
+// physicalInterfaces 只保留以太网和 802.11 类型、且描述不属于可插拔或虚拟设备的网卡
+func physicalInterfaces(interfaces []net.Interface) []net.Interface {
+	adapters, err := adapterAddresses()
+	if err != nil {
+		return nil
+	}
+
+	physical := make(map[int]bool)
+	for _, aa := range adapters {
+		if aa.IfType != windows.IF_TYPE_ETHERNET_CSMACD && aa.IfType != windows.IF_TYPE_IEEE80211 {
+			continue
+		}
+		desc := strings.ToLower(windows.UTF16PtrToString(aa.Description))
+		if containsAny(desc, excludedAdapterKeywords) {
+			continue
+		}
+		index := aa.IfIndex
+		if index == 0 {
+			index = aa.Ipv6IfIndex
+		}
+		physical[int(index)] = true
+	}
+
+	var result []net.Interface
+	for _, iface := range interfaces {
+		if physical[iface.Index] {
+			result = append(result, iface)
+		}
+	}
+	return result
+}
+
+// adapterAddresses 调用 GetAdaptersAddresses，缓冲区不足时按返回的大小重试
+func adapterAddresses() ([]*windows.IpAdapterAddresses, error) {
+	size := uint32(15000)
+	for {
+		buf := make([]byte, size)
+		first := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0]))
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, windows.GAA_FLAG_INCLUDE_PREFIX, 0, first, &size)
+		if err == nil {
+			var adapters []*windows.IpAdapterAddresses
+			for aa := first; aa != nil; aa = aa.Next {
+				adapters = append(adapters, aa)
+			}
+			return adapters, nil
+		}
+		if !errors.Is(err, windows.ERROR_BUFFER_OVERFLOW) || size <= uint32(len(buf)) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// EspeakConfig espeak 合成器配置
+type EspeakConfig struct {
+	Binary    string // 可执行文件，默认依次查找 espeak-ng、espeak
+	Voice     string // 发音人，默认 cmn（普通话）
+	Speed     int    // 语速（每分钟词数），默认 150
+	OutputDir string // 非空时输出 WAV 文件而不直接播放
+}
+
+// binaries 未指定可执行文件时依次查找
+var binaries = []string{"espeak-ng", "espeak"}
+
+// EspeakSynthesizer 基于 espeak/espeak-ng 命令行的合成器
+type EspeakSynthesizer struct {
+	cfg EspeakConfig
+}
+
+// NewEspeakSynthesizer 创建 espeak 合成器
+func NewEspeakSynthesizer(cfg EspeakConfig) *EspeakSynthesizer {
+	if cfg.Binary == "" {
+		cfg.Binary = lookupBinary()
+	}
+	if cfg.Voice == "" {
+		cfg.Voice = "cmn"
+	}
+	if cfg.Speed <= 0 {
+		cfg.Speed = 150
+	}
+	return &EspeakSynthesizer{cfg: cfg}
+}
+
+// Available 检查 espeak 可执行文件是否存在
+func (e *EspeakSynthesizer) Available() bool {
+	_, err := exec.LookPath(e.cfg.Binary)
+	return err == nil
+}
+
+// Speak 调用 espeak 合成语音
+func (e *EspeakSynthesizer) Speak(ctx context.Context, speech Speech) error {
+	args := []string{
+		"-v", e.cfg.Voice,
+		"-s", strconv.Itoa(e.cfg.Speed),
+		// espeak 音量范围 0-200，默认 100
+		"-a", strconv.Itoa(clampVolume(speech.Volume) * 2),
+	}
+
+	if e.cfg.OutputDir != "" {
+		if err := os.MkdirAll(e.cfg.OutputDir, 0755); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("announce_%s.wav", time.Now().Format("20060102_150405.000000000"))
+		args = append(args, "-w", filepath.Join(e.cfg.OutputDir, name))
+	}
+	args = append(args, "--", speech.Text)
+
+	cmd := exec.CommandContext(ctx, e.cfg.Binary, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("espeak 合成失败: %w: %s", err, output)
+	}
+	return nil
+}
+
+// lookupBinary 返回第一个存在的 espeak 可执行文件，都不存在时返回 espeak-ng
+func lookupBinary() string {
+	for _, name := range binaries {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return binaries[0]
+}
+
+func clampVolume(volume int) int {
+	if volume < 0 {
+		return 0
+	}
+	if volume > 100 {
+		return 100
+	}
+	return volume
+}
@@ -0,0 +1,40 @@
+package tts
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnavailable 没有可用的语音合成器
+var ErrUnavailable = errors.New("语音播报不可用")
+
+// Speech 一次语音合成请求
+type Speech struct {
+	Text   string // 播报文本
+	Volume int    // 音量 0-100
+}
+
+// Synthesizer 语音合成接口
+type Synthesizer interface {
+	// Speak 合成并播放语音，阻塞直到播放结束
+	Speak(ctx context.Context, speech Speech) error
+}
+
+// NullSynthesizer 没有可用语音引擎时使用的合成器，不发声也不保存播报内容
+type NullSynthesizer struct{}
+
+// NewNullSynthesizer 创建空合成器
+func NewNullSynthesizer() NullSynthesizer {
+	return NullSynthesizer{}
+}
+
+// Speak 总是返回 ErrUnavailable
+func (NullSynthesizer) Speak(ctx context.Context, speech Speech) error {
+	return ErrUnavailable
+}
+
+// Available 判断合成器是否可以发声
+func Available(s Synthesizer) bool {
+	_, null := s.(NullSynthesizer)
+	return s != nil && !null
+}
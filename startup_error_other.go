@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// showStartupError 优先使用 zenity 弹出错误对话框，不可用时输出到标准错误
+func showStartupError(title, message string) {
+	fmt.Fprintf(os.Stderr, "%s\n%s\n", title, message)
+
+	if path, err := exec.LookPath("zenity"); err == nil {
+		exec.Command(path, "--error", "--title", title, "--text", message).Run()
+	}
+}
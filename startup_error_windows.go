@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "golang.org/x/sys/windows"
+
+// showStartupError 使用系统消息框显示启动错误
+func showStartupError(title, message string) {
+	t, _ := windows.UTF16PtrFromString(title)
+	m, _ := windows.UTF16PtrFromString(message)
+	windows.MessageBox(0, m, t, windows.MB_OK|windows.MB_ICONERROR)
+}
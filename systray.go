@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"log/slog"
 	"os"
 	"time"
 
 	"sw_call/internal/config"
+	"sw_call/pkg/logger"
 
 	"github.com/getlantern/systray"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -33,12 +35,49 @@ func loadTrayIcon(iconPath string) []byte {
 	return nil
 }
 
+// trayLogLevels 托盘菜单中可切换的日志级别
+var trayLogLevels = []string{"debug", "info", "warn", "error"}
+
+// TrayActions 托盘菜单触发的操作
+type TrayActions struct {
+	OnLogLevel    func(level string) // 切换日志级别
+	OnDiagnostics func()             // 导出诊断包
+}
+
 // InitTray 初始化托盘
-func InitTray(trayConfig *config.TrayConfig) {
-	go systray.Run(onReady(trayConfig), onExit)
+func InitTray(trayConfig *config.TrayConfig, actions TrayActions) {
+	go systray.Run(onReady(trayConfig, actions), onExit)
+}
+
+// addLogLevelMenu 添加日志级别子菜单，勾选状态跟随当前日志级别
+func addLogLevelMenu(onLogLevel func(level string)) {
+	levelMenu := systray.AddMenuItem("日志级别", "切换日志级别")
+	items := make(map[string]*systray.MenuItem, len(trayLogLevels))
+	current := logger.LevelName(logger.GetLevel())
+	for _, name := range trayLogLevels {
+		item := levelMenu.AddSubMenuItemCheckbox(name, "切换为 "+name+" 级别", name == current)
+		items[name] = item
+
+		go func(name string) {
+			for range item.ClickedCh {
+				onLogLevel(name)
+			}
+		}(name)
+	}
+
+	logger.OnLevelChange(func(level slog.Level) {
+		current := logger.LevelName(level)
+		for name, item := range items {
+			if name == current {
+				item.Check()
+			} else {
+				item.Uncheck()
+			}
+		}
+	})
 }
 
-func onReady(trayConfig *config.TrayConfig) func() {
+func onReady(trayConfig *config.TrayConfig, actions TrayActions) func() {
 	return func() {
 		systray.SetTitle(trayConfig.Title)
 		systray.SetTooltip(trayConfig.Tooltip)
@@ -54,6 +93,13 @@ func onReady(trayConfig *config.TrayConfig) func() {
 
 		systray.AddSeparator()
 
+		// 日志级别子菜单
+		addLogLevelMenu(actions.OnLogLevel)
+		// 导出诊断包菜单项
+		diagItem := systray.AddMenuItem("导出诊断包", "收集配置、系统信息和日志，用于远程排查问题")
+
+		systray.AddSeparator()
+
 		// 退出菜单项
 		quitItem := systray.AddMenuItem("退出", "退出应用程序")
 
@@ -67,6 +113,9 @@ func onReady(trayConfig *config.TrayConfig) func() {
 				if appCtx != nil {
 					runtime.WindowHide(appCtx)
 				}
+			case <-diagItem.ClickedCh:
+				// 收集系统信息较慢，不阻塞菜单
+				go actions.OnDiagnostics()
 			case <-quitItem.ClickedCh:
 				systray.Quit()
 				if appCtx != nil {
@@ -77,4 +126,13 @@ func onReady(trayConfig *config.TrayConfig) func() {
 	}
 }
 
+// UpdateTray 更新托盘标题、提示文字和图标
+func UpdateTray(trayConfig *config.TrayConfig) {
+	systray.SetTitle(trayConfig.Title)
+	systray.SetTooltip(trayConfig.Tooltip)
+	if iconData := loadTrayIcon(trayConfig.Icon); iconData != nil {
+		systray.SetIcon(iconData)
+	}
+}
+
 func onExit() {}